@@ -0,0 +1,47 @@
+// Package beeptest provides utilities for testing Streamers and code built with Beep.
+package beeptest
+
+import (
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+// Validate wraps s in a beep.Validator, which reports every violation of the Streamer contract
+// as an error of tb.
+//
+//   v := beeptest.Validate(t, myStreamer)
+//   speaker.Play(v)
+func Validate(tb testing.TB, s beep.Streamer) *beep.Validator {
+	v := beep.Validate(s)
+	v.OnViolation = func(violation beep.Violation) {
+		tb.Error(violation)
+	}
+	return v
+}
+
+// checkSizes are the lengths of the sample slices used by Check. They include sizes smaller and
+// bigger than the usual internal buffers to hit the edge cases.
+var checkSizes = []int{1, 512, 7, 1000, 3, 4096, 511, 513, 64}
+
+// Check drains s through a Validator, streaming into slices of various lengths, and reports every
+// violation of the Streamer contract as an error of tb. After s is drained, Check additionally
+// verifies that it stays drained.
+//
+// At most limit samples are streamed, so that infinite Streamers can be checked too. If limit is
+// negative, s must be finite.
+func Check(tb testing.TB, s beep.Streamer, limit int) {
+	tb.Helper()
+	v := Validate(tb, s)
+	buf := make([][2]float64, 4096)
+	for i, total := 0, 0; limit < 0 || total < limit; i++ {
+		n, ok := v.Stream(buf[:checkSizes[i%len(checkSizes)]])
+		if !ok {
+			for j := 0; j < 3; j++ {
+				v.Stream(buf[:checkSizes[j]])
+			}
+			return
+		}
+		total += n
+	}
+}
@@ -0,0 +1,192 @@
+package beeptest
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+	"github.com/pkg/errors"
+)
+
+var update = flag.Bool("beeptest.update", false, "regenerate golden files instead of comparing against them")
+
+// Diff is the result of comparing two sequences of samples.
+type Diff struct {
+	// WantLen and GotLen are the numbers of samples in the compared sequences.
+	WantLen, GotLen int
+
+	// FirstDivergence is the index of the first sample at which the sequences differ by more
+	// than the tolerance, or -1 if they don't diverge. If one sequence is a prefix of the other,
+	// FirstDivergence is the length of the shorter one.
+	FirstDivergence int
+
+	// MaxError is the largest absolute difference of the overlapping samples, found at the index
+	// MaxErrorAt.
+	MaxError   float64
+	MaxErrorAt int
+
+	// SNR is the signal-to-noise ratio in decibels, where the signal is the wanted sequence and
+	// the noise is the difference between the sequences. Identical sequences have an SNR of +Inf.
+	SNR float64
+}
+
+// Equal returns true if the compared sequences didn't diverge.
+func (d Diff) Equal() bool {
+	return d.FirstDivergence < 0
+}
+
+func (d Diff) String() string {
+	if d.Equal() {
+		return fmt.Sprintf("%d samples equal (max error %.3g, SNR %.1f dB)", d.GotLen, d.MaxError, d.SNR)
+	}
+	return fmt.Sprintf("diverged at sample %d (want %d samples, got %d, max error %.3g at %d, SNR %.1f dB)",
+		d.FirstDivergence, d.WantLen, d.GotLen, d.MaxError, d.MaxErrorAt, d.SNR)
+}
+
+// Compare compares the got samples against the wanted ones. Samples which differ by no more than
+// tolerance in both channels are considered equal.
+func Compare(want, got [][2]float64, tolerance float64) Diff {
+	d := Diff{
+		WantLen:         len(want),
+		GotLen:          len(got),
+		FirstDivergence: -1,
+	}
+
+	overlap := len(want)
+	if len(got) < overlap {
+		overlap = len(got)
+	}
+
+	var signal, noise float64
+	for i := 0; i < overlap; i++ {
+		for c := range want[i] {
+			e := math.Abs(want[i][c] - got[i][c])
+			if e > d.MaxError || math.IsNaN(e) {
+				d.MaxError, d.MaxErrorAt = e, i
+			}
+			if d.FirstDivergence < 0 && !(e <= tolerance) {
+				d.FirstDivergence = i
+			}
+			signal += want[i][c] * want[i][c]
+			noise += e * e
+		}
+	}
+	if d.FirstDivergence < 0 && len(want) != len(got) {
+		d.FirstDivergence = overlap
+	}
+
+	switch {
+	case noise == 0:
+		d.SNR = math.Inf(+1)
+	default:
+		d.SNR = 10 * math.Log10(signal/noise)
+	}
+
+	return d
+}
+
+// GoldenOptions configure CompareGolden.
+type GoldenOptions struct {
+	// SampleRate is written to the golden file. Defaults to 44100.
+	SampleRate beep.SampleRate
+
+	// Tolerance is the maximal allowed difference of a sample. Defaults to 1e-6, which is
+	// roughly 8 times the quantization step of the 24-bit golden files.
+	Tolerance float64
+
+	// Update causes the golden file to be regenerated instead of compared against. Running the
+	// tests with the -beeptest.update flag has the same effect.
+	Update bool
+}
+
+// CompareGolden drains s and compares the streamed samples against the golden WAV file at path.
+// If they diverge, the test fails with the position of the first divergence, the maximal error
+// and the SNR.
+//
+// Golden files store samples with 24-bit precision, values outside of [-1, +1] are clipped, so the
+// streamed samples are clipped before the comparison too.
+//
+// When updating, the golden file is overwritten (or created, including the missing directories)
+// with the streamed samples and the test doesn't fail.
+func CompareGolden(tb testing.TB, path string, s beep.Streamer, opts GoldenOptions) {
+	tb.Helper()
+
+	if opts.SampleRate == 0 {
+		opts.SampleRate = 44100
+	}
+	if opts.Tolerance == 0 {
+		opts.Tolerance = 1e-6
+	}
+
+	got := Collect(s)
+	if err := s.Err(); err != nil {
+		tb.Fatalf("beeptest: %s: streamer failed: %v", path, err)
+	}
+	for i := range got {
+		got[i] = [2]float64{clip(got[i][0]), clip(got[i][1])}
+	}
+
+	if opts.Update || *update {
+		if err := writeGolden(path, got, opts.SampleRate); err != nil {
+			tb.Fatalf("beeptest: %s: %v", path, err)
+		}
+		tb.Logf("beeptest: %s: updated golden file with %d samples", path, len(got))
+		return
+	}
+
+	want, err := readGolden(path)
+	if err != nil {
+		tb.Fatalf("beeptest: %s: %v (run with -beeptest.update to create it)", path, err)
+	}
+
+	if d := Compare(want, got, opts.Tolerance); !d.Equal() {
+		tb.Errorf("beeptest: %s: %v", path, d)
+	}
+}
+
+func writeGolden(path string, samples [][2]float64, sr beep.SampleRate) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "failed to create golden file directory")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create golden file")
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	format := beep.Format{SampleRate: sr, NumChannels: 2, Precision: 3}
+	return wav.Encode(f, Data(samples), format)
+}
+
+func readGolden(path string) ([][2]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open golden file")
+	}
+	s, _, err := wav.Decode(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	defer s.Close()
+	samples := Collect(s)
+	return samples, s.Err()
+}
+
+func clip(x float64) float64 {
+	if x < -1 {
+		return -1
+	}
+	if x > +1 {
+		return +1
+	}
+	return x
+}
@@ -0,0 +1,66 @@
+package beeptest_test
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+)
+
+func TestCompare(t *testing.T) {
+	want := beeptest.Collect(beeptest.Noise(1, 1000))
+
+	d := beeptest.Compare(want, want, 0)
+	if !d.Equal() || d.MaxError != 0 || !math.IsInf(d.SNR, +1) {
+		t.Errorf("identical data not equal: %v", d)
+	}
+
+	got := beeptest.Collect(beeptest.Noise(1, 1000))
+	got[600][1] += 0.5
+	got[700][0] += 0.001
+	d = beeptest.Compare(want, got, 0.01)
+	if d.FirstDivergence != 600 || d.MaxErrorAt != 600 || math.Abs(d.MaxError-0.5) > 1e-9 {
+		t.Errorf("unexpected diff: %v", d)
+	}
+
+	d = beeptest.Compare(want, got[:500], 0.01)
+	if d.FirstDivergence != 500 || d.GotLen != 500 {
+		t.Errorf("unexpected diff of a prefix: %v", d)
+	}
+}
+
+func TestCompareGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "beeptest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "golden", "sine.wav")
+
+	sine := func() beep.Streamer {
+		return beeptest.Sine(44100, 440, 0.8, 4410)
+	}
+
+	beeptest.CompareGolden(t, path, sine(), beeptest.GoldenOptions{Update: true})
+	beeptest.CompareGolden(t, path, sine(), beeptest.GoldenOptions{})
+
+	r := &recorder{TB: t}
+	beeptest.CompareGolden(r, path, beeptest.Sine(44100, 441, 0.8, 4410), beeptest.GoldenOptions{})
+	if !r.failed {
+		t.Error("CompareGolden did not fail on different data")
+	}
+}
+
+// recorder records failures instead of reporting them to the wrapped testing.TB.
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
@@ -0,0 +1,124 @@
+package beeptest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/faiface/beep"
+)
+
+// Data returns a StreamSeeker which streams the provided samples. The samples are not copied.
+func Data(samples [][2]float64) beep.StreamSeeker {
+	return &dataStreamer{data: samples}
+}
+
+// Noise returns a StreamSeeker which streams numSamples samples of white noise in the range
+// [-1, +1]. The noise is generated from the seed, so the same seed always produces the same data.
+func Noise(seed int64, numSamples int) beep.StreamSeeker {
+	r := rand.New(rand.NewSource(seed))
+	data := make([][2]float64, numSamples)
+	for i := range data {
+		data[i][0] = r.Float64()*2 - 1
+		data[i][1] = r.Float64()*2 - 1
+	}
+	return Data(data)
+}
+
+// Sine returns a StreamSeeker which streams numSamples samples of a sine wave of the given
+// frequency and amplitude, sampled at the provided sample rate. Both channels are equal.
+func Sine(sr beep.SampleRate, freq, amplitude float64, numSamples int) beep.StreamSeeker {
+	data := make([][2]float64, numSamples)
+	for i := range data {
+		x := amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sr))
+		data[i] = [2]float64{x, x}
+	}
+	return Data(data)
+}
+
+// Impulse returns a StreamSeeker which streams a single sample of value 1 in both channels,
+// followed by numSamples-1 samples of silence.
+func Impulse(numSamples int) beep.StreamSeeker {
+	data := make([][2]float64, numSamples)
+	if numSamples > 0 {
+		data[0] = [2]float64{1, 1}
+	}
+	return Data(data)
+}
+
+// Constant returns a StreamSeeker which streams numSamples copies of the sample.
+func Constant(sample [2]float64, numSamples int) beep.StreamSeeker {
+	data := make([][2]float64, numSamples)
+	for i := range data {
+		data[i] = sample
+	}
+	return Data(data)
+}
+
+// Ramp returns a StreamSeeker which streams numSamples samples linearly rising from -1 to +1 in
+// the left channel and falling from +1 to -1 in the right channel.
+func Ramp(numSamples int) beep.StreamSeeker {
+	data := make([][2]float64, numSamples)
+	for i := range data {
+		x := -1.0
+		if numSamples > 1 {
+			x = 2*float64(i)/float64(numSamples-1) - 1
+		}
+		data[i] = [2]float64{x, -x}
+	}
+	return Data(data)
+}
+
+type dataStreamer struct {
+	data [][2]float64
+	pos  int
+}
+
+func (ds *dataStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	if ds.pos >= len(ds.data) {
+		return 0, false
+	}
+	n = copy(samples, ds.data[ds.pos:])
+	ds.pos += n
+	return n, true
+}
+
+func (ds *dataStreamer) Err() error {
+	return nil
+}
+
+func (ds *dataStreamer) Len() int {
+	return len(ds.data)
+}
+
+func (ds *dataStreamer) Position() int {
+	return ds.pos
+}
+
+func (ds *dataStreamer) Seek(p int) error {
+	if p < 0 || ds.Len() < p {
+		return fmt.Errorf("beeptest: seek position %v out of range [%v, %v]", p, 0, ds.Len())
+	}
+	ds.pos = p
+	return nil
+}
+
+// Collect drains s and returns all of the samples it streamed.
+func Collect(s beep.Streamer) [][2]float64 {
+	var (
+		result [][2]float64
+		buf    [512][2]float64
+	)
+	for {
+		n, ok := s.Stream(buf[:])
+		if !ok {
+			return result
+		}
+		result = append(result, buf[:n]...)
+	}
+}
+
+// CollectN streams at most n samples from s and returns them. It's useful for infinite Streamers.
+func CollectN(s beep.Streamer, n int) [][2]float64 {
+	return Collect(beep.Take(n, s))
+}
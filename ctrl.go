@@ -23,14 +23,19 @@ package beep
 //   speaker.Lock()
 //   ctrl.Paused = true
 //   speaker.Unlock()
+//
+// Alternatively, SetPaused pauses or resumes the Ctrl from any goroutine without locking.
 type Ctrl struct {
 	Streamer Streamer
 	Paused   bool
+
+	paused Flag
 }
 
 // Stream streams the wrapped Streamer, if not nil. If the Streamer is nil, Ctrl acts as drained.
 // When paused, Ctrl streams silence.
 func (c *Ctrl) Stream(samples [][2]float64) (n int, ok bool) {
+	c.paused.Update(&c.Paused)
 	if c.Streamer == nil {
 		return 0, false
 	}
@@ -50,3 +55,9 @@ func (c *Ctrl) Err() error {
 	}
 	return c.Streamer.Err()
 }
+
+// SetPaused sets the Paused field from any goroutine, without locking the speaker. The new value
+// takes effect at the next call to Stream.
+func (c *Ctrl) SetPaused(paused bool) {
+	c.paused.Set(paused)
+}
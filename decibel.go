@@ -0,0 +1,22 @@
+package beep
+
+import "math"
+
+// DBToGain converts decibels to an amplitude gain. 0 dB is the gain of 1, -6 dB is roughly 0.5 and
+// negative infinity is 0, i.e. silence.
+func DBToGain(db float64) float64 {
+	if math.IsInf(db, -1) {
+		return 0
+	}
+	return math.Pow(10, db/20)
+}
+
+// GainToDB converts an amplitude gain to decibels. The sign of the gain is ignored and the gain of
+// 0 is negative infinity.
+func GainToDB(gain float64) float64 {
+	gain = math.Abs(gain)
+	if gain == 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(gain)
+}
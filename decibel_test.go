@@ -0,0 +1,25 @@
+package beep_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+func TestDecibels(t *testing.T) {
+	for _, db := range []float64{-60, -6, 0, 3.5, 12} {
+		if got := beep.GainToDB(beep.DBToGain(db)); math.Abs(got-db) > 1e-9 {
+			t.Errorf("%v dB converted back and forth to %v dB", db, got)
+		}
+	}
+	if beep.DBToGain(math.Inf(-1)) != 0 {
+		t.Error("expected -inf dB to be the gain of 0")
+	}
+	if !math.IsInf(beep.GainToDB(0), -1) {
+		t.Error("expected the gain of 0 to be -inf dB")
+	}
+	if beep.GainToDB(-0.5) != beep.GainToDB(0.5) {
+		t.Error("expected the sign of the gain to be ignored")
+	}
+}
@@ -0,0 +1,207 @@
+package effects
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// Automation is a source of parameter values changing over time. Effect parameters bound to an
+// Automation take a new value for each sample.
+//
+// Effects bind their parameters by Automate methods, e.g. Volume.AutomateVolume. While bound,
+// the field of the parameter holds the last value and a nil Automation unbinds it, so that the
+// parameter keeps that value. Lock the speaker when binding a parameter of a playing effect.
+//
+// An Automation is bound to at most one parameter, because each call to Next moves its time
+// forward.
+type Automation interface {
+	// Next fills values with the values of the parameter for the next len(values) samples.
+	Next(values []float64)
+}
+
+// Curve is the shape of an Envelope segment leading to a breakpoint.
+type Curve int
+
+// Segment shapes of an Envelope.
+const (
+	// Linear changes the value linearly.
+	Linear Curve = iota
+
+	// Exponential changes the value exponentially, that is, by the same ratio per sample. It's
+	// the natural way to change gains and frequencies. If the values of the segment don't have
+	// the same sign or one of them is zero, the segment is linear.
+	Exponential
+
+	// Hold keeps the previous value until the breakpoint, where it jumps to the new value.
+	Hold
+)
+
+// Point is a breakpoint of an Envelope.
+type Point struct {
+	// At is the position of the breakpoint in samples.
+	At int
+
+	// Value is the value of the parameter at the breakpoint.
+	Value float64
+
+	// Curve is the shape of the segment leading from the previous breakpoint to this one.
+	Curve Curve
+}
+
+// PointAt returns a breakpoint at the time t, given the sample rate sr.
+func PointAt(sr beep.SampleRate, t time.Duration, value float64, curve Curve) Point {
+	return Point{At: sr.N(t), Value: value, Curve: curve}
+}
+
+// Envelope is an Automation defined by breakpoints. Before the first breakpoint, the value is the
+// value of the first breakpoint. After the last one, it's the value of the last one.
+//
+//   sr := format.SampleRate
+//   swell := effects.NewEnvelope(
+//       effects.PointAt(sr, 0, -4, effects.Linear),
+//       effects.PointAt(sr, 2*time.Second, 0, effects.Linear),
+//   )
+//   volume := &effects.Volume{Streamer: s, Base: 2}
+//   volume.AutomateVolume(swell)
+type Envelope struct {
+	points []Point
+	pos    int
+	seg    int // index of the first breakpoint after pos
+}
+
+// NewEnvelope creates an Envelope from the breakpoints. They are sorted by their positions.
+func NewEnvelope(points ...Point) *Envelope {
+	e := &Envelope{points: append([]Point(nil), points...)}
+	sort.SliceStable(e.points, func(i, j int) bool {
+		return e.points[i].At < e.points[j].At
+	})
+	e.Seek(0)
+	return e
+}
+
+// Next implements Automation.
+func (e *Envelope) Next(values []float64) {
+	for i := range values {
+		for e.seg < len(e.points) && e.points[e.seg].At <= e.pos {
+			e.seg++
+		}
+		values[i] = e.value(e.pos)
+		e.pos++
+	}
+}
+
+// Value returns the value of the Envelope at the position p, without changing the position.
+func (e *Envelope) Value(p int) float64 {
+	pos, seg := e.pos, e.seg
+	e.Seek(p)
+	v := e.value(p)
+	e.pos, e.seg = pos, seg
+	return v
+}
+
+// Len returns the position of the last breakpoint. The value doesn't change after it.
+func (e *Envelope) Len() int {
+	if len(e.points) == 0 {
+		return 0
+	}
+	return e.points[len(e.points)-1].At
+}
+
+// Position returns the position of the next value returned by Next.
+func (e *Envelope) Position() int {
+	return e.pos
+}
+
+// Seek sets the position of the next value returned by Next, e.g. to restart the Envelope.
+func (e *Envelope) Seek(p int) {
+	e.pos = p
+	e.seg = sort.Search(len(e.points), func(i int) bool {
+		return e.points[i].At > p
+	})
+}
+
+// value returns the value at p, e.seg must be the index of the first breakpoint after p.
+func (e *Envelope) value(p int) float64 {
+	switch {
+	case len(e.points) == 0:
+		return 0
+	case e.seg == 0:
+		return e.points[0].Value
+	case e.seg == len(e.points):
+		return e.points[len(e.points)-1].Value
+	}
+
+	from, to := e.points[e.seg-1], e.points[e.seg]
+	t := float64(p-from.At) / float64(to.At-from.At)
+	switch {
+	case to.Curve == Hold:
+		return from.Value
+	case to.Curve == Exponential && from.Value*to.Value > 0:
+		return from.Value * math.Pow(to.Value/from.Value, t)
+	default:
+		return from.Value + (to.Value-from.Value)*t
+	}
+}
+
+// ControlStreamer returns an Automation which takes the values from the left channel of s, one
+// per sample. It's useful to drive a parameter by an oscillator (an LFO) or by another signal.
+// Control signals with a lower rate can be brought to the sample rate by beep.Resample.
+//
+// When s drains, the last value is held.
+func ControlStreamer(s beep.Streamer) Automation {
+	return &controlStreamer{s: s}
+}
+
+type controlStreamer struct {
+	s       beep.Streamer
+	buf     [][2]float64
+	last    float64
+	drained bool
+}
+
+func (cs *controlStreamer) Next(values []float64) {
+	if cap(cs.buf) < len(values) {
+		cs.buf = make([][2]float64, len(values))
+	}
+	i := 0
+	for i < len(values) && !cs.drained {
+		n, ok := cs.s.Stream(cs.buf[:len(values)-i])
+		for _, sample := range cs.buf[:n] {
+			values[i] = sample[0]
+			i++
+		}
+		if n > 0 {
+			cs.last = values[i-1]
+		}
+		if !ok {
+			cs.drained = true
+		}
+	}
+	for ; i < len(values); i++ {
+		values[i] = cs.last
+	}
+}
+
+// automated is a parameter of an effect, which can be bound to an Automation.
+type automated struct {
+	a      Automation
+	values []float64
+}
+
+// next returns the next n values of the Automation.
+func (p *automated) next(n int) []float64 {
+	values := p.buffer(n)
+	p.a.Next(values)
+	return values
+}
+
+// buffer returns the buffer of n values, which is reused by each call.
+func (p *automated) buffer(n int) []float64 {
+	if cap(p.values) < n {
+		p.values = make([]float64, n)
+	}
+	return p.values[:n]
+}
@@ -0,0 +1,125 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/effects"
+)
+
+func TestEnvelope(t *testing.T) {
+	env := effects.NewEnvelope(
+		effects.Point{At: 8, Value: 1, Curve: effects.Hold},
+		effects.Point{At: 2, Value: 0},
+		effects.Point{At: 4, Value: 4, Curve: effects.Linear},
+		effects.Point{At: 6, Value: 1, Curve: effects.Exponential},
+	)
+	values := make([]float64, 10)
+	env.Next(values[:3])
+	env.Next(values[3:])
+	want := []float64{0, 0, 0, 2, 4, 2, 1, 1, 1, 1}
+	for i := range want {
+		if math.Abs(values[i]-want[i]) > 1e-12 {
+			t.Errorf("value %d: expected %v, got %v", i, want[i], values[i])
+		}
+	}
+	if env.Position() != 10 || env.Len() != 8 {
+		t.Errorf("unexpected position %d or length %d", env.Position(), env.Len())
+	}
+	if env.Value(3) != 2 || env.Position() != 10 {
+		t.Error("Value must not change the position")
+	}
+	env.Seek(5)
+	env.Next(values[:1])
+	if math.Abs(values[0]-2) > 1e-12 {
+		t.Errorf("unexpected value after seek: %v", values[0])
+	}
+}
+
+func TestControlStreamer(t *testing.T) {
+	a := effects.ControlStreamer(beeptest.Ramp(3))
+	values := make([]float64, 5)
+	a.Next(values)
+	ramp := beeptest.Collect(beeptest.Ramp(3))
+	want := []float64{ramp[0][0], ramp[1][0], ramp[2][0], ramp[2][0], ramp[2][0]}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("value %d: expected %v, got %v", i, want[i], values[i])
+		}
+	}
+}
+
+func TestAutomateVolume(t *testing.T) {
+	volume := &effects.Volume{Streamer: beeptest.Constant([2]float64{1, 1}, 6), Base: 2}
+	volume.AutomateVolume(effects.NewEnvelope(
+		effects.Point{At: 0, Value: 0},
+		effects.Point{At: 4, Value: -2, Curve: effects.Linear},
+	))
+	out := beeptest.Collect(volume)
+	want := []float64{1, math.Pow(2, -0.5), 0.5, math.Pow(2, -1.5), 0.25, 0.25}
+	for i := range want {
+		if math.Abs(out[i][0]-want[i]) > 1e-12 {
+			t.Errorf("sample %d: expected %v, got %v", i, want[i], out[i][0])
+		}
+	}
+	if volume.Volume != -2 {
+		t.Errorf("expected the field to hold the last value, got %v", volume.Volume)
+	}
+}
+
+func TestAutomateEqualizer(t *testing.T) {
+	const sr = 44100
+	section := effects.MonoEqualizerSection{F0: 1000, Bf: 200, GB: 3, G0: 0, G: 0}
+	eq := effects.NewEqualizer(beeptest.Sine(sr, 1000, 0.25, 3*sr), sr, effects.MonoEqualizerSections{section})
+
+	// boost the center frequency by 12 dB after a second
+	eq.AutomateG(0, effects.NewEnvelope(
+		effects.Point{At: 0, Value: 0},
+		effects.Point{At: sr, Value: 0},
+		effects.Point{At: sr, Value: 12, Curve: effects.Hold},
+	))
+	out := beeptest.Collect(eq)
+
+	if p := peak(out[sr/2 : sr]); math.Abs(p-0.25) > 0.01 {
+		t.Errorf("expected the sine unchanged at 0 dB, got the peak of %v", p)
+	}
+	if p, want := peak(out[2*sr:]), 0.25*beep.DBToGain(12); math.Abs(p-want) > 0.01 {
+		t.Errorf("expected the sine boosted to %v, got the peak of %v", want, p)
+	}
+}
+
+func TestAutomateEqualizerKeepsState(t *testing.T) {
+	const sr = 44100
+	section := effects.MonoEqualizerSection{F0: 1000, Bf: 200, GB: 3, G0: 0, G: 6}
+	stream := func(automate bool) [][2]float64 {
+		eq := effects.NewEqualizer(beeptest.Sine(sr, 1000, 0.25, sr), sr, effects.MonoEqualizerSections{section})
+		out := make([][2]float64, sr)
+		for i := 0; i < sr; i += 512 {
+			if automate && i == sr/2 {
+				// a constant automation at the current value must not change anything
+				eq.AutomateG(0, effects.NewEnvelope(effects.Point{At: 0, Value: 6}))
+			}
+			end := i + 512
+			if end > sr {
+				end = sr
+			}
+			eq.Stream(out[i:end])
+		}
+		return out
+	}
+
+	// streaming all at once doesn't depend on the state kept between the blocks
+	want := make([][2]float64, sr)
+	effects.NewEqualizer(beeptest.Sine(sr, 1000, 0.25, sr), sr, effects.MonoEqualizerSections{section}).Stream(want)
+	for _, automate := range []bool{false, true} {
+		out := stream(automate)
+		for i := range want {
+			if math.Abs(out[i][0]-want[i][0]) > 1e-9 {
+				t.Errorf("automate %v: sample %d: expected %v, got %v", automate, i, want[i][0], out[i][0])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// CompressorOptions configure a Compressor.
+type CompressorOptions struct {
+	// Threshold is the level in decibels above which the gain is reduced, e.g. -20.
+	Threshold float64
+
+	// Ratio is the amount of compression above the Threshold, e.g. 4 for 4:1, which means that
+	// 4 dB above the Threshold become 1 dB. Values below 1 are treated as 1, which is no
+	// compression.
+	Ratio float64
+
+	// Knee is the width in decibels of the region around the Threshold, where the compression
+	// gradually starts. Zero means a hard knee.
+	Knee float64
+
+	// Attack and Release are the times it takes the gain reduction to follow a rising and a
+	// falling level. They default to 10ms and 100ms.
+	Attack  time.Duration
+	Release time.Duration
+
+	// Makeup is the gain in decibels applied after the compression to make up for the reduced
+	// level.
+	Makeup float64
+
+	// Detection selects peak or RMS level detection. RMSWindow is the averaging window of RMS
+	// detection, which defaults to 10ms.
+	Detection Detection
+	RMSWindow time.Duration
+
+	// Unlinked makes the Compressor process the channels independently. By default, the channels
+	// are linked and reduced by the same gain, so that the stereo image doesn't shift.
+	Unlinked bool
+}
+
+// Compressor is a feed-forward dynamic range compressor. It reduces the level of the wrapped
+// Streamer when it exceeds the threshold.
+//
+//   comp := effects.NewCompressor(s, format.SampleRate, effects.CompressorOptions{
+//       Threshold: -18,
+//       Ratio:     3,
+//       Knee:      6,
+//       Makeup:    4,
+//   })
+type Compressor struct {
+	meter meter // kept first for the alignment of the atomic access
+
+	s        beep.Streamer
+	sr       beep.SampleRate
+	opts     CompressorOptions
+	detect   [2]detector
+	follower [2]follower
+}
+
+// NewCompressor creates a Compressor wrapping s, which has the sample rate sr.
+func NewCompressor(s beep.Streamer, sr beep.SampleRate, opts CompressorOptions) *Compressor {
+	c := &Compressor{s: s, sr: sr}
+	c.SetOptions(opts)
+	return c
+}
+
+// SetOptions changes the options of the Compressor. The current gain reduction is kept. Lock the
+// speaker when calling it on a playing Compressor.
+func (c *Compressor) SetOptions(opts CompressorOptions) {
+	if opts.Ratio < 1 {
+		opts.Ratio = 1
+	}
+	if opts.Attack <= 0 {
+		opts.Attack = 10 * time.Millisecond
+	}
+	if opts.Release <= 0 {
+		opts.Release = 100 * time.Millisecond
+	}
+	c.opts = opts
+	for i := range c.detect {
+		square := c.detect[i].square
+		c.detect[i] = newDetector(opts.Detection, opts.RMSWindow, c.sr)
+		c.detect[i].square = square
+
+		value := c.follower[i].value
+		c.follower[i] = newFollower(opts.Attack, opts.Release, c.sr)
+		c.follower[i].value = value
+	}
+}
+
+// Options returns the options of the Compressor.
+func (c *Compressor) Options() CompressorOptions {
+	return c.opts
+}
+
+// Stream streams the wrapped Streamer compressed.
+func (c *Compressor) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = c.s.Stream(samples)
+	makeup := beep.DBToGain(c.opts.Makeup)
+	max := 0.0
+	for i := range samples[:n] {
+		l := c.detect[0].level(samples[i][0])
+		r := c.detect[1].level(samples[i][1])
+		var gr [2]float64
+		if c.opts.Unlinked {
+			gr[0] = c.follower[0].next(c.reduction(l))
+			gr[1] = c.follower[1].next(c.reduction(r))
+		} else {
+			gr[0] = c.follower[0].next(c.reduction(math.Max(l, r)))
+			gr[1] = gr[0]
+		}
+		for ch := range samples[i] {
+			samples[i][ch] *= beep.DBToGain(-gr[ch]) * makeup
+			max = math.Max(max, gr[ch])
+		}
+	}
+	c.meter.report(max)
+	return n, ok
+}
+
+// reduction returns the gain reduction in decibels required by the level given as an amplitude.
+func (c *Compressor) reduction(level float64) float64 {
+	x := beep.GainToDB(level)
+	over := x - c.opts.Threshold
+	knee := c.opts.Knee
+	slope := 1/c.opts.Ratio - 1
+	switch {
+	case 2*over <= -knee:
+		return 0
+	case 2*over < knee:
+		// the gradual start of the compression within the knee
+		return -slope * (over + knee/2) * (over + knee/2) / (2 * knee)
+	default:
+		return -slope * over
+	}
+}
+
+// GainReduction returns the maximal gain reduction in decibels since the last call. It may be
+// called from any goroutine, e.g. once per frame to drive a gain reduction meter.
+func (c *Compressor) GainReduction() float64 {
+	return c.meter.read()
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (c *Compressor) Err() error {
+	return c.s.Err()
+}
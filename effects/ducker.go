@@ -0,0 +1,192 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// DuckerOptions configure a Ducker.
+type DuckerOptions struct {
+	// Threshold is the level of the key in decibels above which the main Streamer is ducked,
+	// e.g. -30.
+	Threshold float64
+
+	// Amount is the gain reduction of the ducked main Streamer in decibels, e.g. 12.
+	Amount float64
+
+	// Attack and Release are the times it takes to duck the main Streamer and to bring it back.
+	// They default to 10ms and 300ms.
+	Attack  time.Duration
+	Release time.Duration
+
+	// Hold keeps the main Streamer ducked for this long after the key falls below the
+	// Threshold, which avoids pumping in the short pauses of speech.
+	Hold time.Duration
+
+	// Detection selects peak or RMS level detection of the key. RMSWindow is the averaging
+	// window of RMS detection, which defaults to 10ms.
+	Detection Detection
+	RMSWindow time.Duration
+
+	// MixKey mixes the key into the output. By default, the key is only listened to and the
+	// output is the ducked main Streamer alone.
+	MixKey bool
+}
+
+// Ducker lowers the level of the main Streamer while the key Streamer plays, e.g. the music while
+// a dialogue is spoken. It's a sidechain compressor with an infinite ratio above the threshold.
+//
+//   music := effects.NewDucker(music, dialogue, format.SampleRate, effects.DuckerOptions{
+//       Threshold: -40,
+//       Amount:    15,
+//       Hold:      200 * time.Millisecond,
+//       MixKey:    true,
+//   })
+//
+// Both Streamers are streamed at the same pace. The Ducker drains when the main Streamer drains,
+// or when both drain if MixKey is set. A drained key counts as silence.
+//
+// If either of the Streamers fails, the Ducker drains immediately and reports the error through
+// Err. A failed key isn't treated as silence, because the ducking would suddenly stop.
+type Ducker struct {
+	meter meter // kept first for the alignment of the atomic access
+
+	main, key beep.Streamer
+	sr        beep.SampleRate
+	opts      DuckerOptions
+	detect    [2]detector
+	follower  follower
+	hold      int // number of samples left to hold the ducking
+	buf       [][2]float64
+	mainDone  bool
+	keyDone   bool
+	err       error
+}
+
+// NewDucker creates a Ducker of main by key, which both have the sample rate sr.
+func NewDucker(main, key beep.Streamer, sr beep.SampleRate, opts DuckerOptions) *Ducker {
+	d := &Ducker{main: main, key: key, sr: sr}
+	d.SetOptions(opts)
+	return d
+}
+
+// SetOptions changes the options of the Ducker. The current gain reduction is kept. Lock the
+// speaker when calling it on a playing Ducker.
+func (d *Ducker) SetOptions(opts DuckerOptions) {
+	if opts.Attack <= 0 {
+		opts.Attack = 10 * time.Millisecond
+	}
+	if opts.Release <= 0 {
+		opts.Release = 300 * time.Millisecond
+	}
+	d.opts = opts
+
+	for i := range d.detect {
+		square := d.detect[i].square
+		d.detect[i] = newDetector(opts.Detection, opts.RMSWindow, d.sr)
+		d.detect[i].square = square
+	}
+
+	value := d.follower.value
+	d.follower = newFollower(opts.Attack, opts.Release, d.sr)
+	d.follower.value = value
+}
+
+// Options returns the options of the Ducker.
+func (d *Ducker) Options() DuckerOptions {
+	return d.opts
+}
+
+// Stream streams the main Streamer ducked by the key.
+func (d *Ducker) Stream(samples [][2]float64) (n int, ok bool) {
+	if d.drained() {
+		return 0, false
+	}
+
+	// the key is streamed first, so that the samples are left untouched if it fails
+	if cap(d.buf) < len(samples) {
+		d.buf = make([][2]float64, len(samples))
+	}
+	key := d.buf[:len(samples)]
+	kn := 0
+	if !d.keyDone {
+		var kok bool
+		kn, kok = d.key.Stream(key)
+		if !kok || kn < len(key) {
+			d.keyDone = true
+			d.err = d.key.Err()
+		}
+	}
+
+	mn := 0
+	if !d.mainDone && d.err == nil {
+		var mok bool
+		mn, mok = d.main.Stream(samples)
+		if !mok || mn < len(samples) {
+			d.mainDone = true
+			d.err = d.main.Err()
+		}
+	}
+	if d.err != nil {
+		d.mainDone, d.keyDone = true, true
+		return 0, false
+	}
+	for i := kn; i < len(key); i++ {
+		key[i] = [2]float64{}
+	}
+
+	n = mn
+	if d.opts.MixKey && kn > n {
+		n = kn
+		for i := mn; i < n; i++ {
+			samples[i] = [2]float64{}
+		}
+	}
+	if n == 0 && d.drained() {
+		return 0, false
+	}
+
+	threshold := beep.DBToGain(d.opts.Threshold)
+	holdLen := d.sr.N(d.opts.Hold)
+	max := 0.0
+	for i := range samples[:n] {
+		level := math.Max(d.detect[0].level(key[i][0]), d.detect[1].level(key[i][1]))
+		if level > threshold {
+			d.hold = holdLen + 1
+		}
+		target := 0.0
+		if d.hold > 0 {
+			target = d.opts.Amount
+			d.hold--
+		}
+		gr := d.follower.next(target)
+		max = math.Max(max, gr)
+
+		gain := beep.DBToGain(-gr)
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+		if d.opts.MixKey {
+			samples[i][0] += key[i][0]
+			samples[i][1] += key[i][1]
+		}
+	}
+	d.meter.report(max)
+	return n, true
+}
+
+func (d *Ducker) drained() bool {
+	return d.mainDone && (d.keyDone || !d.opts.MixKey)
+}
+
+// GainReduction returns the maximal gain reduction of the main Streamer in decibels since the last
+// call. It may be called from any goroutine, e.g. once per frame to drive a meter.
+func (d *Ducker) GainReduction() float64 {
+	return d.meter.read()
+}
+
+// Err returns the error of the main or the key Streamer which made the Ducker drain, if any.
+func (d *Ducker) Err() error {
+	return d.err
+}
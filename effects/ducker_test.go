@@ -0,0 +1,121 @@
+package effects_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/effects"
+)
+
+func TestDucker(t *testing.T) {
+	// the key plays between 1000 and 2000
+	key := beep.Seq(
+		beeptest.Constant([2]float64{}, 1000),
+		beeptest.Constant([2]float64{0.5, 0.5}, 1000),
+	)
+	opts := effects.DuckerOptions{
+		Threshold: -20,
+		Amount:    12,
+		Attack:    time.Millisecond,
+		Release:   time.Millisecond,
+	}
+	duck := effects.NewDucker(beeptest.Constant([2]float64{1, 1}, 4000), key, 8000, opts)
+	out := beeptest.Collect(duck)
+
+	if len(out) != 4000 {
+		t.Errorf("expected the Ducker to drain with the main Streamer, got %d samples", len(out))
+	}
+	if out[999] != [2]float64{1, 1} {
+		t.Errorf("expected no ducking before the key, got %v", out[999])
+	}
+	if db := beep.GainToDB(out[1999][0]); math.Abs(db+12) > 0.1 {
+		t.Errorf("expected the main Streamer ducked by 12 dB, got %v dB", db)
+	}
+	if math.Abs(out[3999][0]-1) > 1e-3 {
+		t.Errorf("expected the main Streamer to come back after the key, got %v", out[3999])
+	}
+	if gr := duck.GainReduction(); math.Abs(gr-12) > 0.1 {
+		t.Errorf("expected about 12 dB of gain reduction, got %v", gr)
+	}
+
+	opts.MixKey = true
+	mixed := beeptest.Collect(effects.NewDucker(beeptest.Constant([2]float64{1, 1}, 500), beeptest.Constant([2]float64{0.5, 0.5}, 1000), 8000, opts))
+	if len(mixed) != 1000 || mixed[999] != [2]float64{0.5, 0.5} {
+		t.Errorf("expected the key mixed into the output until it drains, got %d samples", len(mixed))
+	}
+	// the RMS level of each key channel is measured separately, so a key in one channel isn't
+	// averaged with the silence of the other one
+	left := beeptest.Constant([2]float64{0.5, 0}, 4000)
+	opts = effects.DuckerOptions{Threshold: -7, Amount: 12, Detection: effects.RMS}
+	out = beeptest.Collect(effects.NewDucker(beeptest.Constant([2]float64{1, 1}, 4000), left, 8000, opts))
+	if db := beep.GainToDB(out[3999][0]); math.Abs(db+12) > 0.1 {
+		t.Errorf("expected a -6 dB key in the left channel to duck by 12 dB, got %v dB", db)
+	}
+}
+
+func TestDuckerError(t *testing.T) {
+	errBroken := errors.New("broken")
+	opts := effects.DuckerOptions{Threshold: -20, Amount: 12, MixKey: true}
+
+	tests := map[string]struct {
+		main, key beep.Streamer
+	}{
+		"main": {&failing{n: 1000, err: errBroken}, beeptest.Constant([2]float64{}, 4000)},
+		"key":  {beeptest.Constant([2]float64{}, 4000), &failing{n: 1000, err: errBroken}},
+	}
+	for name, test := range tests {
+		duck := effects.NewDucker(test.main, test.key, 8000, opts)
+		v := beeptest.Validate(t, duck)
+		buf := make([][2]float64, 500)
+		for i := 0; i < 2; i++ {
+			if n, ok := v.Stream(buf); n != len(buf) || !ok {
+				t.Fatalf("%s: expected the Ducker to stream before the failure, got %d, %v", name, n, ok)
+			}
+		}
+		for i := 0; i < 2; i++ {
+			if n, ok := v.Stream(buf); n != 0 || ok {
+				t.Errorf("%s: expected the Ducker to drain on the failure, got %d, %v", name, n, ok)
+			}
+		}
+		if duck.Err() != errBroken {
+			t.Errorf("%s: expected the error of the %s Streamer, got %v", name, name, duck.Err())
+		}
+	}
+
+	beeptest.Check(t, effects.NewDucker(&failing{err: errBroken}, beeptest.Constant([2]float64{}, 4000), 8000, opts), -1)
+	beeptest.Check(t, effects.NewDucker(beeptest.Constant([2]float64{}, 4000), &failing{err: errBroken}, 8000, opts), -1)
+}
+
+// failing is a Streamer which streams n samples of silence and then fails with err.
+type failing struct {
+	n      int
+	err    error
+	failed bool
+}
+
+func (f *failing) Stream(samples [][2]float64) (n int, ok bool) {
+	if f.n == 0 {
+		f.failed = true
+		return 0, false
+	}
+	n = len(samples)
+	if n > f.n {
+		n = f.n
+	}
+	for i := range samples[:n] {
+		samples[i] = [2]float64{}
+	}
+	f.n -= n
+	return n, true
+}
+
+func (f *failing) Err() error {
+	if !f.failed {
+		return nil
+	}
+	return f.err
+}
@@ -0,0 +1,98 @@
+package effects
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// Detection selects how dynamics processors measure the level of a signal.
+type Detection int
+
+// Level detection modes.
+const (
+	// Peak measures the absolute value of each sample. It reacts to every peak, which suits
+	// limiting and gating.
+	Peak Detection = iota
+
+	// RMS measures the average power over a short window. It's closer to the perceived loudness,
+	// which suits gentle compression.
+	RMS
+)
+
+// detector measures the level of one channel.
+type detector struct {
+	mode   Detection
+	coef   float64 // smoothing coefficient of the mean square
+	square float64 // the mean square
+}
+
+func newDetector(mode Detection, window time.Duration, sr beep.SampleRate) detector {
+	if window <= 0 {
+		window = 10 * time.Millisecond
+	}
+	return detector{mode: mode, coef: timeCoef(window, sr)}
+}
+
+// level returns the level of the signal, including the sample x, as an amplitude.
+func (d *detector) level(x float64) float64 {
+	if d.mode == RMS {
+		d.square = d.coef*d.square + (1-d.coef)*x*x
+		return math.Sqrt(d.square)
+	}
+	return math.Abs(x)
+}
+
+// timeCoef returns the coefficient of a one-pole smoother with the time constant t.
+func timeCoef(t time.Duration, sr beep.SampleRate) float64 {
+	if t <= 0 {
+		return 0
+	}
+	return math.Exp(-1 / (t.Seconds() * float64(sr)))
+}
+
+// follower smooths a gain reduction in decibels with separate attack and release times.
+type follower struct {
+	attack, release float64 // coefficients
+	value           float64
+}
+
+func newFollower(attack, release time.Duration, sr beep.SampleRate) follower {
+	return follower{attack: timeCoef(attack, sr), release: timeCoef(release, sr)}
+}
+
+// next moves the value toward target, using the attack time if the target is higher.
+func (f *follower) next(target float64) float64 {
+	coef := f.release
+	if target > f.value {
+		coef = f.attack
+	}
+	f.value = coef*f.value + (1-coef)*target
+	return f.value
+}
+
+// meter holds the maximal gain reduction since the last read. It's reported by the playback and
+// read from any goroutine.
+type meter struct {
+	bits uint64 // accessed atomically
+}
+
+// report records a gain reduction in decibels.
+func (m *meter) report(db float64) {
+	for {
+		old := atomic.LoadUint64(&m.bits)
+		if db <= math.Float64frombits(old) {
+			return
+		}
+		if atomic.CompareAndSwapUint64(&m.bits, old, math.Float64bits(db)) {
+			return
+		}
+	}
+}
+
+// read returns the maximal gain reduction since the last read and resets it.
+func (m *meter) read() float64 {
+	return math.Float64frombits(atomic.SwapUint64(&m.bits, 0))
+}
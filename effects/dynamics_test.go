@@ -0,0 +1,77 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/effects"
+)
+
+func TestCompressor(t *testing.T) {
+	// a full scale sine, compressed 4:1 above -12 dB, settles at -12 + 12/4 = -9 dB
+	comp := effects.NewCompressor(beeptest.Sine(8000, 100, 1, 8000), 8000, effects.CompressorOptions{
+		Threshold: -12,
+		Ratio:     4,
+		Attack:    time.Millisecond,
+		Release:   200 * time.Millisecond,
+	})
+	out := beeptest.Collect(comp)
+	peak := 0.0
+	for _, sample := range out[4000:] {
+		peak = math.Max(peak, math.Abs(sample[0]))
+	}
+	if db := beep.GainToDB(peak); math.Abs(db+9) > 0.5 {
+		t.Errorf("expected the peak level of about -9 dB, got %v dB", db)
+	}
+	if gr := comp.GainReduction(); gr < 8.5 || gr > 9.5 {
+		t.Errorf("expected about 9 dB of gain reduction, got %v", gr)
+	}
+
+	quiet := effects.NewCompressor(beeptest.Sine(8000, 100, 0.1, 1000), 8000, effects.CompressorOptions{
+		Threshold: -12,
+		Ratio:     4,
+		Knee:      6,
+		Makeup:    6,
+		Detection: effects.RMS,
+	})
+	want := beeptest.Collect(&effects.Fader{Streamer: beeptest.Sine(8000, 100, 0.1, 1000), DB: 6})
+	if d := beeptest.Compare(want, beeptest.Collect(quiet), 1e-9); !d.Equal() {
+		t.Errorf("expected a signal below the knee to only get the makeup gain: %v", d)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	data := append(beeptest.Collect(beeptest.Sine(8000, 100, 0.25, 400)),
+		beeptest.Collect(beeptest.Sine(8000, 100, 2, 400))...)
+	data = append(data, beeptest.Collect(beeptest.Sine(8000, 100, 0.25, 4000))...)
+
+	lim := effects.NewLimiter(beeptest.Data(data), 8000, effects.LimiterOptions{Threshold: -6})
+	out := beeptest.Collect(lim)
+	if len(out) != len(data)+lim.Latency() {
+		t.Errorf("expected the delayed samples to be streamed, got %d samples", len(out))
+	}
+	ceiling := beep.DBToGain(-6)
+	for i, sample := range out {
+		if math.Abs(sample[0]) > ceiling+1e-9 || math.Abs(sample[1]) > ceiling+1e-9 {
+			t.Fatalf("sample %d exceeds the threshold: %v", i, sample)
+		}
+	}
+	if gr := lim.GainReduction(); math.Abs(gr-12) > 0.1 {
+		t.Errorf("expected about 12 dB of gain reduction, got %v", gr)
+	}
+
+	// unlinked channels are limited separately
+	unlinked := effects.NewLimiter(beeptest.Constant([2]float64{1, 0.25}, 1000), 8000, effects.LimiterOptions{
+		Threshold: -6,
+		Unlinked:  true,
+	})
+	last := beeptest.Collect(unlinked)[999]
+	if math.Abs(last[0]-ceiling) > 1e-9 || last[1] != 0.25 {
+		t.Errorf("expected only the left channel to be limited, got %v", last)
+	}
+
+	beeptest.Check(t, effects.NewLimiter(beeptest.Noise(1, 3000), 44100, effects.LimiterOptions{}), 10000)
+}
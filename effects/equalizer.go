@@ -8,16 +8,30 @@ import (
 
 type (
 
+	// Equalizer is a parametric equalizer created by NewEqualizer.
+	//
 	// This parametric equalizer is based on the GK Nilsen's post at:
 	// https://octovoid.com/2017/11/04/coding-a-parametric-equalizer-for-audio-applications/
-	equalizer struct {
+	Equalizer struct {
 		streamer beep.Streamer
+		fs       float64
 		sections []section
 	}
 
 	section struct {
-		a, b         [2][]float64
-		xPast, yPast [][2]float64
+		a, b [2][]float64
+		x, y [2][2]float64 // the last two input and output samples of each channel
+
+		params [2]MonoEqualizerSection // parameters of the left and the right channel
+		auto   *sectionAutomation      // non-nil while any parameter is automated
+	}
+
+	// sectionAutomation filters with the coefficients recomputed from the automated parameters
+	// whenever their values change.
+	sectionAutomation struct {
+		params [2]MonoEqualizerSection
+		f0, g  automated
+		a, b   [2][3]float64 // the coefficients of params
 	}
 
 	// EqualizerSections is the interfacd that is passed into NewEqualizer
@@ -70,11 +84,12 @@ type (
 	MonoEqualizerSections []MonoEqualizerSection
 )
 
-// NewEqualizer returns a beep.Streamer that modifies the stream based on the EqualizerSection slice that is passed in.
+// NewEqualizer returns an Equalizer that modifies the stream based on the EqualizerSection slice that is passed in.
 // The SampleRate (sr) must match that of the Streamer.
-func NewEqualizer(st beep.Streamer, sr beep.SampleRate, s EqualizerSections) beep.Streamer {
-	return &equalizer{
+func NewEqualizer(st beep.Streamer, sr beep.SampleRate, s EqualizerSections) *Equalizer {
+	return &Equalizer{
 		streamer: st,
+		fs:       float64(sr),
 		sections: s.sections(float64(sr)),
 	}
 }
@@ -96,42 +111,93 @@ func (m StereoEqualizerSections) sections(fs float64) []section {
 }
 
 // Stream streams the wrapped Streamer modified by Equalizer.
-func (e *equalizer) Stream(samples [][2]float64) (n int, ok bool) {
+func (e *Equalizer) Stream(samples [][2]float64) (n int, ok bool) {
 	n, ok = e.streamer.Stream(samples)
-	for _, s := range e.sections {
-		s.apply(samples)
+	for i := range e.sections {
+		s := &e.sections[i]
+		if s.auto != nil {
+			s.auto.apply(s, samples[:n], e.fs)
+			continue
+		}
+		s.apply(samples[:n])
 	}
 	return n, ok
 }
 
 // Err propagates the wrapped Streamer's errors.
-func (e *equalizer) Err() error {
+func (e *Equalizer) Err() error {
 	return e.streamer.Err()
 }
 
+// AutomateF0 binds the center frequency F0 of the i-th section to a, in both channels of a stereo
+// section, see Automation.
+//
+//   sweep := effects.NewEnvelope(
+//       effects.PointAt(sr, 0, 200, effects.Exponential),
+//       effects.PointAt(sr, 4*time.Second, 5000, effects.Exponential),
+//   )
+//   eq.AutomateF0(0, sweep)
+func (e *Equalizer) AutomateF0(i int, a Automation) {
+	e.automate(i, func(sa *sectionAutomation) { sa.f0.a = a })
+}
+
+// AutomateG binds the boost/cut gain G of the i-th section to a, like AutomateF0.
+func (e *Equalizer) AutomateG(i int, a Automation) {
+	e.automate(i, func(sa *sectionAutomation) { sa.g.a = a })
+}
+
+func (e *Equalizer) automate(i int, bind func(*sectionAutomation)) {
+	s := &e.sections[i]
+	if s.auto == nil {
+		// the filter keeps its state, so that binding doesn't click
+		s.auto = &sectionAutomation{params: s.params}
+		for c, m := range s.params {
+			s.auto.a[c], s.auto.b[c] = m.coefficients(e.fs)
+		}
+	}
+	bind(s.auto)
+	if s.auto.f0.a != nil || s.auto.g.a != nil {
+		return
+	}
+
+	// nothing is automated anymore, continue with the last values
+	s.params = s.auto.params
+	s.auto = nil
+	for c, m := range s.params {
+		a, b := m.coefficients(e.fs)
+		s.a[c], s.b[c] = a[:], b[:]
+	}
+}
+
 func (m MonoEqualizerSection) section(fs float64) section {
+	a, b := m.coefficients(fs)
+	return section{
+		a:      [2][]float64{a[:], a[:]},
+		b:      [2][]float64{b[:], b[:]},
+		params: [2]MonoEqualizerSection{m, m},
+	}
+}
+
+func (m MonoEqualizerSection) coefficients(fs float64) (a, b [3]float64) {
 	beta := math.Tan(m.Bf/2.0*math.Pi/(fs/2.0)) *
 		math.Sqrt(math.Abs(math.Pow(math.Pow(10, m.GB/20.0), 2.0)-
 			math.Pow(math.Pow(10.0, m.G0/20.0), 2.0))) /
 		math.Sqrt(math.Abs(math.Pow(math.Pow(10.0, m.G/20.0), 2.0)-
 			math.Pow(math.Pow(10.0, m.GB/20.0), 2.0)))
 
-	b := []float64{
+	b = [3]float64{
 		(math.Pow(10.0, m.G0/20.0) + math.Pow(10.0, m.G/20.0)*beta) / (1 + beta),
 		(-2 * math.Pow(10.0, m.G0/20.0) * math.Cos(m.F0*math.Pi/(fs/2.0))) / (1 + beta),
 		(math.Pow(10.0, m.G0/20) - math.Pow(10.0, m.G/20.0)*beta) / (1 + beta),
 	}
 
-	a := []float64{
+	a = [3]float64{
 		1.0,
 		-2 * math.Cos(m.F0*math.Pi/(fs/2.0)) / (1 + beta),
 		(1 - beta) / (1 + beta),
 	}
 
-	return section{
-		a: [2][]float64{a, a},
-		b: [2][]float64{b, b},
-	}
+	return a, b
 }
 
 func (s StereoEqualizerSection) section(fs float64) section {
@@ -139,56 +205,50 @@ func (s StereoEqualizerSection) section(fs float64) section {
 	r := s.Right.section(fs)
 
 	return section{
-		a: [2][]float64{l.a[0], r.a[0]},
-		b: [2][]float64{l.b[0], r.b[0]},
+		a:      [2][]float64{l.a[0], r.a[0]},
+		b:      [2][]float64{l.b[0], r.b[0]},
+		params: [2]MonoEqualizerSection{s.Left, s.Right},
 	}
 }
 
 func (s *section) apply(x [][2]float64) {
-	ord := len(s.a[0]) - 1
-	np := len(x) - 1
-
-	if np < ord {
-		x = append(x, make([][2]float64, ord-np)...)
-		np = ord
+	for i := range x {
+		for c := range x[i] {
+			x[i][c] = s.filter(c, x[i][c], s.a[c], s.b[c])
+		}
 	}
+}
 
-	y := make([][2]float64, len(x))
+// filter filters the next sample in of the channel c by the coefficients a and b.
+func (s *section) filter(c int, in float64, a, b []float64) float64 {
+	out := (b[0]*in + b[1]*s.x[c][0] + b[2]*s.x[c][1] - a[1]*s.y[c][0] - a[2]*s.y[c][1]) / a[0]
+	s.x[c] = [2]float64{in, s.x[c][0]}
+	s.y[c] = [2]float64{out, s.y[c][0]}
+	return out
+}
 
-	if len(s.xPast) < len(x) {
-		s.xPast = append(s.xPast, make([][2]float64, len(x)-len(s.xPast))...)
+func (sa *sectionAutomation) apply(s *section, x [][2]float64, fs float64) {
+	var f0, g []float64
+	if sa.f0.a != nil {
+		f0 = sa.f0.next(len(x))
 	}
-
-	if len(s.yPast) < len(x) {
-		s.yPast = append(s.yPast, make([][2]float64, len(x)-len(s.yPast))...)
+	if sa.g.a != nil {
+		g = sa.g.next(len(x))
 	}
-
-	for i := 0; i < len(x); i++ {
-		for j := 0; j < ord+1; j++ {
-			if i-j < 0 {
-				y[i][0] = y[i][0] + s.b[0][j]*s.xPast[len(s.xPast)-j][0]
-				y[i][1] = y[i][1] + s.b[1][j]*s.xPast[len(s.xPast)-j][1]
-			} else {
-				y[i][0] = y[i][0] + s.b[0][j]*x[i-j][0]
-				y[i][1] = y[i][1] + s.b[1][j]*x[i-j][1]
+	for i := range x {
+		for c := range x[i] {
+			p := &sa.params[c]
+			changed := false
+			if f0 != nil && f0[i] != p.F0 {
+				p.F0, changed = f0[i], true
 			}
-		}
-
-		for j := 0; j < ord; j++ {
-			if i-j-1 < 0 {
-				y[i][0] = y[i][0] - s.a[0][j+1]*s.yPast[len(s.yPast)-j-1][0]
-				y[i][1] = y[i][1] - s.a[1][j+1]*s.yPast[len(s.yPast)-j-1][1]
-			} else {
-				y[i][0] = y[i][0] - s.a[0][j+1]*y[i-j-1][0]
-				y[i][1] = y[i][1] - s.a[1][j+1]*y[i-j-1][1]
+			if g != nil && g[i] != p.G {
+				p.G, changed = g[i], true
+			}
+			if changed {
+				sa.a[c], sa.b[c] = p.coefficients(fs)
 			}
+			x[i][c] = s.filter(c, x[i][c], sa.a[c][:], sa.b[c][:])
 		}
-
-		y[i][0] = y[i][0] / s.a[0][0]
-		y[i][1] = y[i][1] / s.a[1][0]
 	}
-
-	s.xPast = x[:]
-	s.yPast = y[:]
-	copy(x, y)
 }
@@ -0,0 +1,79 @@
+package effects
+
+import (
+	"math"
+
+	"github.com/faiface/beep"
+)
+
+// Fader sets the level of the wrapped Streamer in decibels, like a fader on a mixing console. It's
+// the way to set the level of a track played through a beep.Mixer or the speaker.
+//
+// DB of 0 means no change, -6 roughly halves the amplitude and negative infinity silences the
+// output. Muted silences the output regardless of DB.
+//
+// Smoothing is the number of samples over which changes of DB and Muted are spread to avoid zipper
+// noise. Zero means the changes are applied at once.
+//
+// Changing the fields of a playing Fader requires locking the speaker. SetDB and SetMuted change
+// them from any goroutine without locking.
+type Fader struct {
+	Streamer beep.Streamer
+	DB       float64
+	Muted    bool
+
+	Smoothing int // in samples, e.g. sr.N(10 * time.Millisecond)
+
+	db    param
+	muted beep.Flag
+	mute  smoother
+}
+
+// Stream streams the wrapped Streamer at the level set by DB and Muted.
+func (f *Fader) Stream(samples [][2]float64) (n int, ok bool) {
+	f.muted.Update(&f.Muted)
+	n, ok = f.Streamer.Stream(samples)
+	mute := 1.0
+	if f.Muted {
+		mute = 0
+	}
+	f.mute.set(mute, f.Smoothing)
+
+	gains := f.db.values(&f.DB, n, f.Smoothing, beep.DBToGain)
+	for i := range samples[:n] {
+		gain := gains[i] * f.mute.next()
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (f *Fader) Err() error {
+	return f.Streamer.Err()
+}
+
+// SetDB sets the DB field from any goroutine. The new value takes effect at the next call to
+// Stream.
+func (f *Fader) SetDB(db float64) {
+	f.db.Set(db)
+}
+
+// SetMuted sets the Muted field from any goroutine. The new value takes effect at the next call to
+// Stream.
+func (f *Fader) SetMuted(muted bool) {
+	f.muted.Set(muted)
+}
+
+// AutomateDB binds DB to a, see Automation.
+func (f *Fader) AutomateDB(a Automation) {
+	f.db.Automate(a)
+}
+
+// Gain returns the amplitude gain of the Fader, 0 if muted.
+func (f *Fader) Gain() float64 {
+	if f.Muted || math.IsInf(f.DB, -1) {
+		return 0
+	}
+	return beep.DBToGain(f.DB)
+}
@@ -0,0 +1,40 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/effects"
+)
+
+func TestFader(t *testing.T) {
+	fader := &effects.Fader{Streamer: beeptest.Constant([2]float64{1, -1}, 30), DB: -6}
+	samples := make([][2]float64, 10)
+	fader.Stream(samples)
+	if want := beep.DBToGain(-6); math.Abs(samples[0][0]-want) > 1e-12 || math.Abs(samples[0][1]+want) > 1e-12 {
+		t.Errorf("expected the gain of %v, got %v", want, samples[0])
+	}
+
+	fader.SetDB(math.Inf(-1))
+	fader.Stream(samples)
+	if samples[0] != [2]float64{} {
+		t.Errorf("expected silence at -inf dB, got %v", samples[0])
+	}
+
+	fader.DB = 0
+	fader.Muted = true
+	fader.Stream(samples)
+	if samples[0] != [2]float64{} || fader.Gain() != 0 {
+		t.Error("expected silence when muted")
+	}
+
+	volume := &effects.Volume{Base: 2}
+	volume.SetDB(-12)
+	volume.Streamer = beeptest.Constant([2]float64{1, 1}, 1)
+	volume.Stream(samples[:1])
+	if math.Abs(volume.DB()+12) > 1e-9 {
+		t.Errorf("expected Volume at -12 dB, got %v dB", volume.DB())
+	}
+}
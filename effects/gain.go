@@ -7,17 +7,28 @@ import "github.com/faiface/beep"
 //
 // Note that gain is not equivalent to the human perception of volume. Human perception of volume is
 // roughly exponential, while gain only amplifies linearly.
+//
+// Smoothing is the number of samples over which changes of Gain are spread to avoid zipper noise.
+// Zero means the changes are applied at once.
+//
+// Changing Gain of a playing Gain requires locking the speaker, SetGain changes it from any
+// goroutine without locking.
 type Gain struct {
 	Streamer beep.Streamer
 	Gain     float64
+
+	Smoothing int // in samples, e.g. sr.N(10 * time.Millisecond)
+
+	gain param
 }
 
 // Stream streams the wrapped Streamer amplified by Gain.
 func (g *Gain) Stream(samples [][2]float64) (n int, ok bool) {
 	n, ok = g.Streamer.Stream(samples)
+	gains := g.gain.values(&g.Gain, n, g.Smoothing, func(gain float64) float64 { return 1 + gain })
 	for i := range samples[:n] {
-		samples[i][0] *= 1 + g.Gain
-		samples[i][1] *= 1 + g.Gain
+		samples[i][0] *= gains[i]
+		samples[i][1] *= gains[i]
 	}
 	return n, ok
 }
@@ -26,3 +37,25 @@ func (g *Gain) Stream(samples [][2]float64) (n int, ok bool) {
 func (g *Gain) Err() error {
 	return g.Streamer.Err()
 }
+
+// SetGain sets the Gain field from any goroutine. The new value takes effect at the next call to
+// Stream.
+func (g *Gain) SetGain(gain float64) {
+	g.gain.Set(gain)
+}
+
+// AutomateGain binds Gain to a, see Automation.
+func (g *Gain) AutomateGain(a Automation) {
+	g.gain.Automate(a)
+}
+
+// DB returns the gain in decibels, that is, 1+Gain converted to decibels.
+func (g *Gain) DB() float64 {
+	return beep.GainToDB(1 + g.Gain)
+}
+
+// SetDB sets Gain, so that the gain is db decibels, from any goroutine like SetGain. Negative
+// infinity sets Gain to -1, which silences the output.
+func (g *Gain) SetDB(db float64) {
+	g.SetGain(beep.DBToGain(db) - 1)
+}
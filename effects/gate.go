@@ -0,0 +1,203 @@
+package effects
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// GateOptions configure a Gate.
+type GateOptions struct {
+	// Threshold is the level in decibels above which the Gate opens, e.g. -45.
+	Threshold float64
+
+	// Hysteresis is how many decibels below the Threshold the level must fall for the Gate to
+	// close. It keeps the Gate from chattering when the level hovers around the Threshold.
+	Hysteresis float64
+
+	// Ratio makes the Gate a downward expander. While closed, each decibel below the Threshold
+	// becomes Ratio decibels, e.g. 2 for 1:2 expansion. Zero means a plain gate, which
+	// attenuates by Range as soon as it closes.
+	Ratio float64
+
+	// Range is the maximal attenuation in decibels of the closed Gate. Zero means 100 dB, which
+	// is inaudible.
+	Range float64
+
+	// Attack is the time it takes the Gate to open, Hold is how long it stays open after the
+	// level falls below the Threshold and Hysteresis and Release is the time it takes to close.
+	// Attack and Release default to 1ms and 100ms.
+	Attack  time.Duration
+	Hold    time.Duration
+	Release time.Duration
+
+	// Detection selects peak or RMS level detection. RMSWindow is the averaging window of RMS
+	// detection, which defaults to 10ms.
+	Detection Detection
+	RMSWindow time.Duration
+
+	// HighPass is the cutoff frequency in Hz of a high-pass filter applied to the signal before
+	// the level detection, so that rumble and hum don't open the Gate. The output is not
+	// filtered. Zero disables the filter.
+	HighPass float64
+}
+
+// Gate is a noise gate and downward expander. It attenuates the wrapped Streamer while its level
+// is below the threshold, e.g. to remove the background hiss between the phrases of a voice
+// recording.
+//
+//   gate := effects.NewGate(voice, format.SampleRate, effects.GateOptions{
+//       Threshold:  -45,
+//       Hysteresis: 6,
+//       Hold:       50 * time.Millisecond,
+//       HighPass:   100,
+//   })
+//
+// The channels are linked, so both are attenuated by the same gain.
+type Gate struct {
+	meter meter  // kept first for the alignment of the atomic access
+	open  uint32 // accessed atomically
+
+	s        beep.Streamer
+	sr       beep.SampleRate
+	opts     GateOptions
+	detect   [2]detector
+	follower follower
+	filter   [2]highPass
+	isOpen   bool
+	hold     int // number of samples left to hold the Gate open
+}
+
+// NewGate creates a Gate wrapping s, which has the sample rate sr.
+func NewGate(s beep.Streamer, sr beep.SampleRate, opts GateOptions) *Gate {
+	g := &Gate{s: s, sr: sr}
+	g.SetOptions(opts)
+	g.follower.value = g.opts.Range // start closed
+	return g
+}
+
+// SetOptions changes the options of the Gate. The current state of the Gate is kept. Lock the
+// speaker when calling it on a playing Gate.
+func (g *Gate) SetOptions(opts GateOptions) {
+	if opts.Range <= 0 {
+		opts.Range = 100
+	}
+	if opts.Attack <= 0 {
+		opts.Attack = time.Millisecond
+	}
+	if opts.Release <= 0 {
+		opts.Release = 100 * time.Millisecond
+	}
+	g.opts = opts
+
+	for i := range g.detect {
+		square := g.detect[i].square
+		g.detect[i] = newDetector(opts.Detection, opts.RMSWindow, g.sr)
+		g.detect[i].square = square
+	}
+
+	// the follower attacks when the attenuation rises, that is, when the Gate closes
+	value := g.follower.value
+	g.follower = newFollower(opts.Release, opts.Attack, g.sr)
+	g.follower.value = value
+
+	for i := range g.filter {
+		g.filter[i].setCutoff(opts.HighPass, g.sr)
+	}
+}
+
+// Options returns the options of the Gate.
+func (g *Gate) Options() GateOptions {
+	return g.opts
+}
+
+// Stream streams the wrapped Streamer gated.
+func (g *Gate) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = g.s.Stream(samples)
+
+	open := beep.DBToGain(g.opts.Threshold)
+	closed := beep.DBToGain(g.opts.Threshold - g.opts.Hysteresis)
+	holdLen := g.sr.N(g.opts.Hold)
+	max := 0.0
+	for i := range samples[:n] {
+		l := g.filter[0].filter(samples[i][0])
+		r := g.filter[1].filter(samples[i][1])
+		level := math.Max(g.detect[0].level(l), g.detect[1].level(r))
+
+		switch {
+		case level > open:
+			g.isOpen, g.hold = true, holdLen
+		case level < closed && g.hold > 0:
+			g.hold--
+		case level < closed:
+			g.isOpen = false
+		}
+
+		target := 0.0
+		if !g.isOpen {
+			target = g.opts.Range
+			if g.opts.Ratio > 0 {
+				below := g.opts.Threshold - beep.GainToDB(level)
+				target = math.Min(target, math.Max(0, below*(g.opts.Ratio-1)))
+			}
+		}
+		gr := g.follower.next(target)
+		max = math.Max(max, gr)
+
+		gain := beep.DBToGain(-gr)
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+	}
+
+	var isOpen uint32
+	if g.isOpen {
+		isOpen = 1
+	}
+	atomic.StoreUint32(&g.open, isOpen)
+	g.meter.report(max)
+	return n, ok
+}
+
+// IsOpen reports whether the Gate was open at the end of the last streamed block. It may be
+// called from any goroutine.
+func (g *Gate) IsOpen() bool {
+	return atomic.LoadUint32(&g.open) == 1
+}
+
+// GainReduction returns the maximal attenuation in decibels since the last call. It may be called
+// from any goroutine, e.g. once per frame to drive a meter.
+func (g *Gate) GainReduction() float64 {
+	return g.meter.read()
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (g *Gate) Err() error {
+	return g.s.Err()
+}
+
+// highPass is a one-pole high-pass filter of one channel.
+type highPass struct {
+	coef   float64 // zero disables the filter
+	x1, y1 float64
+}
+
+func (h *highPass) setCutoff(cutoff float64, sr beep.SampleRate) {
+	if cutoff <= 0 {
+		h.coef = 0
+		return
+	}
+	rc := 1 / (2 * math.Pi * cutoff)
+	dt := 1 / float64(sr)
+	h.coef = rc / (rc + dt)
+}
+
+func (h *highPass) filter(x float64) float64 {
+	if h.coef == 0 {
+		return x
+	}
+	y := h.coef * (h.y1 + x - h.x1)
+	h.x1, h.y1 = x, y
+	return y
+}
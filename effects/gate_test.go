@@ -0,0 +1,72 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/effects"
+)
+
+func TestGate(t *testing.T) {
+	hiss := func(n int) beep.Streamer {
+		return &effects.Fader{Streamer: beeptest.Noise(1, n), DB: -60}
+	}
+	voice := beeptest.Sine(8000, 440, 0.3, 4000)
+	gate := effects.NewGate(beep.Seq(hiss(4000), voice, hiss(4000)), 8000, effects.GateOptions{
+		Threshold:  -40,
+		Hysteresis: 6,
+		Hold:       50 * time.Millisecond,
+		Release:    50 * time.Millisecond,
+	})
+
+	samples := make([][2]float64, 4000)
+	gate.Stream(samples)
+	if gate.IsOpen() || peak(samples) > beep.DBToGain(-140) {
+		t.Errorf("expected the hiss to be removed, peak %v dB", beep.GainToDB(peak(samples)))
+	}
+	gate.GainReduction()
+
+	gate.Stream(samples)
+	if !gate.IsOpen() || math.Abs(peak(samples[100:])-0.3) > 1e-3 {
+		t.Errorf("expected the voice to pass, peak %v", peak(samples[100:]))
+	}
+	if gr := gate.GainReduction(); gr < 90 {
+		t.Errorf("expected the gain reduction to include the closed gate, got %v", gr)
+	}
+
+	gate.Stream(samples)
+	if gate.IsOpen() || peak(samples[2000:]) > beep.DBToGain(-140) {
+		t.Errorf("expected the gate to close after the voice, peak %v dB", beep.GainToDB(peak(samples[2000:])))
+	}
+
+	// a loud rumble doesn't open the gate with the sidechain high-pass filter
+	rumble := effects.NewGate(beeptest.Sine(8000, 20, 0.1, 4000), 8000, effects.GateOptions{
+		Threshold: -40,
+		HighPass:  500,
+	})
+	beeptest.Collect(rumble)
+	if rumble.IsOpen() {
+		t.Error("expected the high-pass filter to keep the gate closed")
+	}
+	// the RMS level of each channel is measured separately, so a signal in one channel isn't
+	// averaged with the silence of the other one
+	left := effects.NewGate(beeptest.Constant([2]float64{0.5, 0}, 4000), 8000, effects.GateOptions{
+		Threshold: -7,
+		Detection: effects.RMS,
+	})
+	beeptest.Collect(left)
+	if !left.IsOpen() {
+		t.Error("expected a -6 dB signal in the left channel to open the gate")
+	}
+}
+
+func peak(samples [][2]float64) float64 {
+	max := 0.0
+	for _, sample := range samples {
+		max = math.Max(max, math.Max(math.Abs(sample[0]), math.Abs(sample[1])))
+	}
+	return max
+}
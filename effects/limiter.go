@@ -0,0 +1,231 @@
+package effects
+
+import (
+	"math"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// LimiterOptions configure a Limiter.
+type LimiterOptions struct {
+	// Threshold is the maximal output level in decibels relative to the full scale, e.g. -1.
+	// Zero means 0 dBFS. Positive values are treated as zero.
+	Threshold float64
+
+	// Lookahead is how much in advance the Limiter reacts to peaks. The output is delayed by
+	// this amount. Defaults to 5ms.
+	Lookahead time.Duration
+
+	// Release is the time it takes the gain to recover after a peak. Defaults to 100ms.
+	Release time.Duration
+
+	// Unlinked makes the Limiter process the channels independently. By default, the channels
+	// are linked and reduced by the same gain, so that the stereo image doesn't shift.
+	Unlinked bool
+}
+
+// Limiter is a brickwall look-ahead limiter. It guarantees that the level of the wrapped Streamer
+// doesn't exceed the threshold, while reducing the gain smoothly ahead of the peaks instead of
+// clipping them.
+//
+// The output is delayed by Latency samples. When the wrapped Streamer drains, the Limiter streams
+// the delayed samples before draining too.
+type Limiter struct {
+	meter meter // kept first for the alignment of the atomic access
+
+	s         beep.Streamer
+	sr        beep.SampleRate
+	opts      LimiterOptions
+	threshold float64 // linear
+	release   float64 // release coefficient per sample
+
+	delay   [][2]float64 // delay line of the input
+	pos     int          // number of processed samples
+	planner [2]gainPlanner
+	drained bool
+	tail    int // number of delayed samples left to stream after s drained
+}
+
+// gainPlanner computes the gain of one channel (or of both linked channels) of a Limiter.
+//
+// The gain required by each sample is held at its minimum over the look-ahead window and then
+// smoothed by a moving average over the same window. This makes the gain ramp down smoothly
+// before a peak and guarantees that the peak itself gets the required gain. The release then
+// only slows down the recovery.
+type gainPlanner struct {
+	reqs  []float64 // required gains in the window, ring buffer
+	minq  []int     // monotonic queue of positions for the sliding minimum, ring buffer
+	qhead int       // index of the first element of minq
+	qlen  int       // number of elements in minq
+	holds []float64 // held minimums in the window, ring buffer
+	sum   float64   // sum of holds
+	gain  float64   // the last applied gain
+}
+
+// NewLimiter creates a Limiter wrapping s, which has the sample rate sr.
+func NewLimiter(s beep.Streamer, sr beep.SampleRate, opts LimiterOptions) *Limiter {
+	l := &Limiter{s: s, sr: sr}
+	l.SetOptions(opts)
+	return l
+}
+
+// lookaheadLen returns the length of the look-ahead window in samples.
+func lookaheadLen(opts LimiterOptions, sr beep.SampleRate) int {
+	if opts.Lookahead <= 0 {
+		opts.Lookahead = 5 * time.Millisecond
+	}
+	n := sr.N(opts.Lookahead)
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// SetOptions changes the options of the Limiter. Lock the speaker when calling it on a playing
+// Limiter.
+//
+// Changing the Lookahead changes the delay of the output and restarts the Limiter, which causes a
+// short glitch. The other options can be changed seamlessly.
+func (l *Limiter) SetOptions(opts LimiterOptions) {
+	if opts.Threshold > 0 {
+		opts.Threshold = 0
+	}
+	if opts.Release <= 0 {
+		opts.Release = 100 * time.Millisecond
+	}
+	l.opts = opts
+	l.threshold = beep.DBToGain(opts.Threshold)
+	l.release = timeCoef(opts.Release, l.sr)
+
+	n := lookaheadLen(opts, l.sr)
+	if len(l.delay) == n {
+		return
+	}
+	l.delay = make([][2]float64, n)
+	l.pos = 0
+	for i := range l.planner {
+		p := &l.planner[i]
+		p.reqs = make([]float64, n)
+		p.minq = make([]int, n)
+		p.holds = make([]float64, n)
+		p.qhead, p.qlen = 0, 0
+		p.sum, p.gain = float64(n), 1
+		for j := range p.holds {
+			p.reqs[j], p.holds[j] = 1, 1
+		}
+	}
+}
+
+// Options returns the options of the Limiter.
+func (l *Limiter) Options() LimiterOptions {
+	return l.opts
+}
+
+// Latency returns the number of samples the Limiter delays the signal by.
+func (l *Limiter) Latency() int {
+	return len(l.delay) - 1
+}
+
+// Stream streams the wrapped Streamer limited and delayed.
+func (l *Limiter) Stream(samples [][2]float64) (n int, ok bool) {
+	if !l.drained {
+		var sok bool
+		n, sok = l.s.Stream(samples)
+		if !sok || n < len(samples) {
+			l.drained, l.tail = true, l.Latency()
+		}
+	}
+	// after s drained, the delayed samples are pushed out by silence
+	for n < len(samples) && l.tail > 0 {
+		samples[n] = [2]float64{}
+		n++
+		l.tail--
+	}
+	if n == 0 && l.drained {
+		return 0, false
+	}
+	l.process(samples[:n])
+	return n, true
+}
+
+// required returns the gain required to bring the level x under the threshold.
+func (l *Limiter) required(x float64) float64 {
+	if x > l.threshold {
+		return l.threshold / x
+	}
+	return 1
+}
+
+func (l *Limiter) process(samples [][2]float64) {
+	n := len(l.delay)
+	max := 1.0
+	for i := range samples {
+		var gain [2]float64
+		if l.opts.Unlinked {
+			gain[0] = l.planner[0].next(l.required(math.Abs(samples[i][0])), l.pos, l.release)
+			gain[1] = l.planner[1].next(l.required(math.Abs(samples[i][1])), l.pos, l.release)
+		} else {
+			peak := math.Max(math.Abs(samples[i][0]), math.Abs(samples[i][1]))
+			gain[0] = l.planner[0].next(l.required(peak), l.pos, l.release)
+			gain[1] = gain[0]
+		}
+		max = math.Min(max, math.Min(gain[0], gain[1]))
+
+		// the delay line is n samples long, but the sample entering it now is the one with the
+		// newest required gain, so the output is delayed by n-1 samples
+		idx := l.pos % n
+		in := samples[i]
+		out := l.delay[(l.pos+1)%n]
+		if n == 1 {
+			out = in
+		}
+		l.delay[idx] = in
+		samples[i] = [2]float64{out[0] * gain[0], out[1] * gain[1]}
+
+		l.pos++
+	}
+	l.meter.report(-beep.GainToDB(max))
+}
+
+// next returns the gain for the sample at pos, which requires the gain req.
+func (p *gainPlanner) next(req float64, pos int, release float64) float64 {
+	n := len(p.reqs)
+
+	// sliding minimum of the required gains over the last n samples
+	idx := pos % n
+	p.reqs[idx] = req
+	if p.qlen > 0 && p.minq[p.qhead] <= pos-n {
+		p.qhead = (p.qhead + 1) % n
+		p.qlen--
+	}
+	for p.qlen > 0 && p.reqs[p.minq[(p.qhead+p.qlen-1)%n]%n] >= req {
+		p.qlen--
+	}
+	p.minq[(p.qhead+p.qlen)%n] = pos
+	p.qlen++
+	hold := p.reqs[p.minq[p.qhead]%n]
+
+	// moving average of the held minimums over the last n samples
+	p.sum += hold - p.holds[idx]
+	p.holds[idx] = hold
+	target := p.sum / float64(n)
+
+	if target < p.gain {
+		p.gain = target
+	} else {
+		p.gain = target + (p.gain-target)*release
+	}
+	return p.gain
+}
+
+// GainReduction returns the maximal gain reduction in decibels since the last call. It may be
+// called from any goroutine, e.g. once per frame to drive a gain reduction meter.
+func (l *Limiter) GainReduction() float64 {
+	return l.meter.read()
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (l *Limiter) Err() error {
+	return l.s.Err()
+}
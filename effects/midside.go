@@ -0,0 +1,172 @@
+package effects
+
+import "github.com/faiface/beep"
+
+// MidSideEncode converts the wrapped Streamer from left/right to mid/side. The left channel of the
+// returned Streamer is the mid component (L+R)/2 and the right channel is the side component
+// (L-R)/2.
+//
+// The returned Streamer propagates s's errors through Err.
+func MidSideEncode(s beep.Streamer) beep.Streamer {
+	return &midSideCoder{s, encodeMidSide}
+}
+
+// MidSideDecode converts the wrapped Streamer from mid/side, as produced by MidSideEncode, back to
+// left/right.
+//
+// The returned Streamer propagates s's errors through Err.
+func MidSideDecode(s beep.Streamer) beep.Streamer {
+	return &midSideCoder{s, decodeMidSide}
+}
+
+func encodeMidSide(sample [2]float64) [2]float64 {
+	return [2]float64{(sample[0] + sample[1]) / 2, (sample[0] - sample[1]) / 2}
+}
+
+func decodeMidSide(sample [2]float64) [2]float64 {
+	return [2]float64{sample[0] + sample[1], sample[0] - sample[1]}
+}
+
+type midSideCoder struct {
+	Streamer beep.Streamer
+	code     func([2]float64) [2]float64
+}
+
+func (c *midSideCoder) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = c.Streamer.Stream(samples)
+	for i := range samples[:n] {
+		samples[i] = c.code(samples[i])
+	}
+	return n, ok
+}
+
+func (c *midSideCoder) Err() error {
+	return c.Streamer.Err()
+}
+
+// MidSide processes the mid and the side component of s separately. The mid and side functions
+// build an effect chain around the Streamer they're given, which streams the mid or the side
+// component in both channels. The left channels of the chains are then decoded back to
+// left/right. A nil function leaves the component unchanged.
+//
+//   // attenuate only the side, leave the mid as is
+//   s = effects.MidSide(s, nil, func(side beep.Streamer) beep.Streamer {
+//       return &effects.Gain{Streamer: side, Gain: -0.5}
+//   })
+//
+// The chains must stream their input sample by sample, without changing its timing, like all the
+// effects in this package do.
+//
+// If s or one of the chains fails, the returned Streamer drains and reports the error through
+// Err.
+func MidSide(s beep.Streamer, mid, side func(beep.Streamer) beep.Streamer) beep.Streamer {
+	ms := &midSide{
+		s:      s,
+		midIn:  &component{},
+		sideIn: &component{},
+	}
+	ms.mid, ms.side = beep.Streamer(ms.midIn), beep.Streamer(ms.sideIn)
+	if mid != nil {
+		ms.mid = mid(ms.midIn)
+	}
+	if side != nil {
+		ms.side = side(ms.sideIn)
+	}
+	return ms
+}
+
+type midSide struct {
+	s         beep.Streamer
+	midIn     *component
+	sideIn    *component
+	mid, side beep.Streamer // the chains
+	buf       [][2]float64
+	err       error
+}
+
+// component streams a block of a mid or a side component prepared by midSide.
+type component struct {
+	samples [][2]float64
+}
+
+func (c *component) Stream(samples [][2]float64) (n int, ok bool) {
+	if len(c.samples) == 0 {
+		return 0, false
+	}
+	n = copy(samples, c.samples)
+	c.samples = c.samples[n:]
+	return n, true
+}
+
+func (c *component) Err() error {
+	return nil
+}
+
+func (ms *midSide) Stream(samples [][2]float64) (n int, ok bool) {
+	if ms.err != nil {
+		return 0, false
+	}
+
+	// everything is processed in buf, so that the samples are left untouched on a failure
+	if cap(ms.buf) < 5*len(samples) {
+		ms.buf = make([][2]float64, 5*len(samples))
+	}
+	in := ms.buf[:len(samples)]
+	n, ok = ms.s.Stream(in)
+	if !ok {
+		ms.err = ms.s.Err()
+	}
+	if n == 0 || ms.err != nil {
+		return 0, false
+	}
+
+	buf := ms.buf[len(samples):]
+	midIn, sideIn := buf[:n], buf[n:2*n]
+	midOut, sideOut := buf[2*n:3*n], buf[3*n:4*n]
+	for i := range in[:n] {
+		m := encodeMidSide(in[i])
+		midIn[i] = [2]float64{m[0], m[0]}
+		sideIn[i] = [2]float64{m[1], m[1]}
+	}
+
+	ms.midIn.samples = midIn
+	ms.sideIn.samples = sideIn
+	mid := streamFull(ms.mid, midOut)
+	side := streamFull(ms.side, sideOut)
+	if ms.err = ms.mid.Err(); ms.err == nil {
+		ms.err = ms.side.Err()
+	}
+	if ms.err != nil {
+		return 0, false
+	}
+
+	for i := range samples[:n] {
+		var m [2]float64
+		if i < mid {
+			m[0] = midOut[i][0]
+		}
+		if i < side {
+			m[1] = sideOut[i][0]
+		}
+		samples[i] = decodeMidSide(m)
+	}
+	return n, ok
+}
+
+func (ms *midSide) Err() error {
+	return ms.err
+}
+
+// streamFull streams s into samples until they're full or s drains and returns the number of
+// streamed samples.
+func streamFull(s beep.Streamer, samples [][2]float64) int {
+	n := 0
+	for n < len(samples) {
+		sn, ok := s.Stream(samples[n:])
+		n += sn
+		if !ok {
+			break
+		}
+	}
+	return n
+}
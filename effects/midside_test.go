@@ -0,0 +1,67 @@
+package effects_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/effects"
+)
+
+func TestMidSide(t *testing.T) {
+	data := beeptest.Collect(beeptest.Noise(1, 1000))
+
+	roundTrip := beeptest.Collect(effects.MidSideDecode(effects.MidSideEncode(beeptest.Data(data))))
+	if d := beeptest.Compare(data, roundTrip, 1e-12); !d.Equal() {
+		t.Errorf("encoding and decoding changed the samples: %v", d)
+	}
+
+	// processing the side only is the same as changing the width
+	ms := beeptest.Collect(effects.MidSide(beeptest.Data(data), nil, func(side beep.Streamer) beep.Streamer {
+		return &effects.Gain{Streamer: side, Gain: 0.5}
+	}))
+	width := beeptest.Collect(&effects.Width{Streamer: beeptest.Data(data), Width: 1.5})
+	if d := beeptest.Compare(width, ms, 1e-12); !d.Equal() {
+		t.Errorf("side processing differs from Width: %v", d)
+	}
+
+	mono := beeptest.Collect(&effects.Width{Streamer: beeptest.Data(data), Width: 0})
+	for i := range mono {
+		if math.Abs(mono[i][0]-mono[i][1]) > 1e-12 {
+			t.Fatalf("expected mono output at the width of 0, sample %d is %v", i, mono[i])
+		}
+	}
+
+	beeptest.Check(t, effects.MidSide(beeptest.Noise(2, 5000), func(mid beep.Streamer) beep.Streamer {
+		return &effects.Fader{Streamer: mid, DB: -3}
+	}, nil), 10000)
+}
+
+func TestMidSideError(t *testing.T) {
+	errBroken := errors.New("broken")
+
+	ms := effects.MidSide(&failing{n: 1000, err: errBroken}, nil, nil)
+	v := beeptest.Validate(t, ms)
+	buf := make([][2]float64, 500)
+	for i := 0; i < 2; i++ {
+		if n, ok := v.Stream(buf); n != len(buf) || !ok {
+			t.Fatalf("expected MidSide to stream before the failure, got %d, %v", n, ok)
+		}
+	}
+	if n, ok := v.Stream(buf); n != 0 || ok {
+		t.Errorf("expected MidSide to drain on the failure, got %d, %v", n, ok)
+	}
+	if ms.Err() != errBroken {
+		t.Errorf("expected the error of the Streamer, got %v", ms.Err())
+	}
+
+	ms = effects.MidSide(beeptest.Noise(1, 1000), nil, func(side beep.Streamer) beep.Streamer {
+		return &failing{err: errBroken}
+	})
+	beeptest.Check(t, ms, -1)
+	if ms.Err() != errBroken {
+		t.Errorf("expected the error of the chain, got %v", ms.Err())
+	}
+}
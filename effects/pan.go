@@ -1,36 +1,139 @@
 package effects
 
-import "github.com/faiface/beep"
+import (
+	"math"
+
+	"github.com/faiface/beep"
+)
 
 // Pan balances the wrapped Streamer between the left and the right channel. The Pan field value of
 // -1 means that both original channels go through the left channel. The value of +1 means the same
 // for the right channel. The value of 0 changes nothing.
+//
+// Mode selects how the channels are balanced and Law selects how the level of a channel changes
+// as it moves between the sides. The zero values give the original behavior of Pan, a stereo pan
+// with the linear law.
+//
+// Smoothing is the number of samples over which changes of Pan are spread to avoid zipper noise.
+// Zero means the changes are applied at once.
+//
+// Changing Pan of a playing Pan requires locking the speaker, SetPan changes it from any goroutine
+// without locking.
 type Pan struct {
 	Streamer beep.Streamer
 	Pan      float64
+	Mode     PanMode
+	Law      PanLaw
+
+	Smoothing int // in samples, e.g. sr.N(10 * time.Millisecond)
+
+	pan param
 }
 
 // Stream streams the wrapped Streamer balanced by Pan.
 func (p *Pan) Stream(samples [][2]float64) (n int, ok bool) {
 	n, ok = p.Streamer.Stream(samples)
-	switch {
-	case p.Pan < 0:
-		for i := range samples[:n] {
-			r := samples[i][1]
-			samples[i][0] += -p.Pan * r
-			samples[i][1] -= -p.Pan * r
-		}
-	case p.Pan > 0:
-		for i := range samples[:n] {
-			l := samples[i][0]
-			samples[i][0] -= p.Pan * l
-			samples[i][1] += p.Pan * l
-		}
+	pans := p.pan.values(&p.Pan, n, p.Smoothing, nil)
+	for i := range samples[:n] {
+		p.balance(&samples[i], pans[i])
 	}
 	return n, ok
 }
 
+// PanMode selects how Pan balances the channels.
+type PanMode int
+
+// Pan modes.
+const (
+	// StereoPan moves the channel on the opposite side over to the side of Pan, keeping the
+	// stereo image. At +1, the left channel is fully moved to the right.
+	StereoPan PanMode = iota
+
+	// MonoPan downmixes the channels to mono and places the mono signal between the sides. This
+	// is the true panning of a mono source.
+	MonoPan
+
+	// BalancePan only attenuates the channel on the opposite side, like the balance knob of an
+	// amplifier. Nothing moves between the channels and Pan of 0 changes nothing, regardless of
+	// the Law.
+	BalancePan
+)
+
+// PanLaw selects the levels of a signal panned between the sides. The laws are named after the
+// level of a signal panned to the center, relative to the level at a side.
+type PanLaw int
+
+// Pan laws.
+const (
+	// LinearPan changes the levels linearly, which is the -6 dB law. The sum of the amplitudes
+	// is constant, but a signal in the center sounds quieter than at the sides.
+	LinearPan PanLaw = iota
+
+	// ConstantPowerPan is the -3 dB law. The sum of the powers is constant, so the perceived
+	// loudness doesn't change while panning uncorrelated signals.
+	ConstantPowerPan
+
+	// CompromisePan is the -4.5 dB law, halfway between LinearPan and ConstantPowerPan.
+	CompromisePan
+
+	// Minus6dBPan is the -6 dB law, the same as LinearPan, for naming the law explicitly.
+	Minus6dBPan = LinearPan
+)
+
+// gains returns the gains of the left and right channel for a signal at position x, where 0 is
+// the left side and 1 is the right side.
+func (law PanLaw) gains(x float64) (l, r float64) {
+	switch law {
+	case ConstantPowerPan:
+		return math.Cos(x * math.Pi / 2), math.Sin(x * math.Pi / 2)
+	case CompromisePan:
+		return math.Sqrt((1 - x) * math.Cos(x*math.Pi/2)), math.Sqrt(x * math.Sin(x*math.Pi/2))
+	default:
+		return 1 - x, x
+	}
+}
+
+// balance balances a single sample by pan.
+func (p *Pan) balance(sample *[2]float64, pan float64) {
+	pan = math.Max(-1, math.Min(pan, +1))
+	switch p.Mode {
+	case MonoPan:
+		m := (sample[0] + sample[1]) / 2
+		l, r := p.Law.gains((pan + 1) / 2)
+		sample[0], sample[1] = m*l, m*r
+	case BalancePan:
+		l, r := p.Law.gains((pan + 1) / 2)
+		cl, cr := p.Law.gains(0.5)
+		sample[0] *= math.Min(1, l/cl)
+		sample[1] *= math.Min(1, r/cr)
+	default:
+		switch {
+		case pan < 0:
+			// the right channel moves from the right side (1) to the left side (0)
+			l, r := p.Law.gains(1 + pan)
+			sample[0] += l * sample[1]
+			sample[1] *= r
+		case pan > 0:
+			// the left channel moves from the left side (0) to the right side (1)
+			l, r := p.Law.gains(pan)
+			sample[1] += r * sample[0]
+			sample[0] *= l
+		}
+	}
+}
+
 // Err propagates the wrapped Streamer's errors.
 func (p *Pan) Err() error {
 	return p.Streamer.Err()
 }
+
+// SetPan sets the Pan field from any goroutine. The new value takes effect at the next call to
+// Stream.
+func (p *Pan) SetPan(pan float64) {
+	p.pan.Set(pan)
+}
+
+// AutomatePan binds Pan to a, see Automation.
+func (p *Pan) AutomatePan(a Automation) {
+	p.pan.Automate(a)
+}
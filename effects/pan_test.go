@@ -0,0 +1,44 @@
+package effects_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/effects"
+)
+
+func TestPan(t *testing.T) {
+	pan := func(mode effects.PanMode, law effects.PanLaw, value float64, sample [2]float64) [2]float64 {
+		p := &effects.Pan{Streamer: beeptest.Constant(sample, 1), Pan: value, Mode: mode, Law: law}
+		return beeptest.Collect(p)[0]
+	}
+	near := func(a, b [2]float64) bool {
+		return math.Abs(a[0]-b[0]) < 1e-9 && math.Abs(a[1]-b[1]) < 1e-9
+	}
+
+	if got := pan(effects.StereoPan, effects.LinearPan, 0.25, [2]float64{1, 0.5}); !near(got, [2]float64{0.75, 0.75}) {
+		t.Errorf("unexpected stereo pan: %v", got)
+	}
+
+	for law, db := range map[effects.PanLaw]float64{
+		effects.Minus6dBPan:      -6.0206,
+		effects.ConstantPowerPan: -3.0103,
+		effects.CompromisePan:    -4.5154,
+	} {
+		got := pan(effects.MonoPan, law, 0, [2]float64{1, 1})
+		if math.Abs(beep.GainToDB(got[0])-db) > 1e-3 || math.Abs(got[0]-got[1]) > 1e-9 {
+			t.Errorf("law %d: expected %v dB in the center, got %v", law, db, got)
+		}
+		if got := pan(effects.MonoPan, law, -1, [2]float64{1, 1}); !near(got, [2]float64{1, 0}) {
+			t.Errorf("law %d: expected the full level on the left side, got %v", law, got)
+		}
+		if got := pan(effects.BalancePan, law, 0, [2]float64{1, 0.5}); !near(got, [2]float64{1, 0.5}) {
+			t.Errorf("law %d: expected balance in the center to change nothing, got %v", law, got)
+		}
+		if got := pan(effects.BalancePan, law, 1, [2]float64{1, 0.5}); !near(got, [2]float64{0, 0.5}) {
+			t.Errorf("law %d: expected balance to the right to mute the left channel, got %v", law, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package effects
+
+import "github.com/faiface/beep"
+
+// param is a float64 parameter of an effect. It can be set from any goroutine, its changes are
+// smoothed and it can be bound to an Automation. The effect keeps the value in an exported field
+// and gets the values for each sample from values.
+type param struct {
+	pending beep.Param
+	smooth  smoother
+	auto    automated
+}
+
+// Set sets the value of the parameter from any goroutine. It takes effect at the next call to
+// values.
+func (p *param) Set(v float64) {
+	p.pending.Set(v)
+}
+
+// Automate binds the parameter to a, a nil Automation unbinds it.
+func (p *param) Automate(a Automation) {
+	p.auto.a = a
+}
+
+// values returns the values of the parameter for the next n samples, mapped by f, e.g. from
+// decibels to gain. A nil f maps nothing. The value set by Set is picked up into *v first. When
+// automated, the last value is left in *v. Otherwise, the changes of *v are smoothed over
+// smoothing samples after the mapping.
+func (p *param) values(v *float64, n, smoothing int, f func(float64) float64) []float64 {
+	p.pending.Update(v)
+	if f == nil {
+		f = identity
+	}
+
+	if p.auto.a != nil {
+		values := p.auto.next(n)
+		if n > 0 {
+			*v = values[n-1]
+		}
+		for i := range values {
+			values[i] = f(values[i])
+		}
+		p.smooth.set(f(*v), 0)
+		return values
+	}
+
+	p.smooth.set(f(*v), smoothing)
+	values := p.auto.buffer(n)
+	for i := range values {
+		values[i] = p.smooth.next()
+	}
+	return values
+}
+
+func identity(x float64) float64 {
+	return x
+}
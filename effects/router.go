@@ -0,0 +1,132 @@
+package effects
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/faiface/beep"
+)
+
+// Matrix is a channel routing matrix. Matrix[out][in] is the gain of the input channel in in the
+// output channel out, where 0 is the left and 1 is the right channel. Negative gains invert the
+// phase.
+type Matrix [2][2]float64
+
+// IdentityMatrix returns a Matrix which changes nothing.
+func IdentityMatrix() Matrix {
+	return Matrix{{1, 0}, {0, 1}}
+}
+
+// DownmixMatrix returns a Matrix which downmixes both channels to mono, like Mono.
+func DownmixMatrix() Matrix {
+	return Matrix{{0.5, 0.5}, {0.5, 0.5}}
+}
+
+// SwapMatrix returns a Matrix which swaps the channels, like Swap.
+func SwapMatrix() Matrix {
+	return Matrix{{0, 1}, {1, 0}}
+}
+
+// LeftOnlyMatrix returns a Matrix which plays the left channel on both sides.
+func LeftOnlyMatrix() Matrix {
+	return Matrix{{1, 0}, {1, 0}}
+}
+
+// RightOnlyMatrix returns a Matrix which plays the right channel on both sides.
+func RightOnlyMatrix() Matrix {
+	return Matrix{{0, 1}, {0, 1}}
+}
+
+// InvertMatrix returns a Matrix which inverts the polarity of both channels.
+func InvertMatrix() Matrix {
+	return Matrix{{-1, 0}, {0, -1}}
+}
+
+// apply routes a single sample through m.
+func (m *Matrix) apply(sample [2]float64) [2]float64 {
+	return [2]float64{
+		m[0][0]*sample[0] + m[0][1]*sample[1],
+		m[1][0]*sample[0] + m[1][1]*sample[1],
+	}
+}
+
+// Router routes the channels of the wrapped Streamer through a Matrix. It generalizes Mono, Swap
+// and Pan to any mapping of the input channels to the output channels.
+//
+//   router := &effects.Router{Streamer: s, Matrix: effects.DownmixMatrix()}
+//
+// Smoothing is the number of samples over which changes of the Matrix are spread to avoid
+// clicks. Zero means the changes are applied at once.
+//
+// Changing the Matrix of a playing Router requires locking the speaker, SetMatrix changes it from
+// any goroutine without locking.
+type Router struct {
+	Streamer beep.Streamer
+	Matrix   Matrix
+
+	Smoothing int // in samples, e.g. sr.N(10 * time.Millisecond)
+
+	matrix matrixParam
+	smooth [2][2]smoother
+}
+
+// Stream streams the wrapped Streamer routed through the Matrix.
+func (r *Router) Stream(samples [][2]float64) (n int, ok bool) {
+	r.matrix.Update(&r.Matrix)
+	n, ok = r.Streamer.Stream(samples)
+
+	steady := true
+	for out := range r.smooth {
+		for in := range r.smooth[out] {
+			r.smooth[out][in].set(r.Matrix[out][in], r.Smoothing)
+			steady = steady && r.smooth[out][in].steady()
+		}
+	}
+	if steady {
+		m := r.Matrix
+		for i := range samples[:n] {
+			samples[i] = m.apply(samples[i])
+		}
+		return n, ok
+	}
+	for i := range samples[:n] {
+		var m Matrix
+		for out := range m {
+			for in := range m[out] {
+				m[out][in] = r.smooth[out][in].next()
+			}
+		}
+		samples[i] = m.apply(samples[i])
+	}
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (r *Router) Err() error {
+	return r.Streamer.Err()
+}
+
+// SetMatrix sets the Matrix field from any goroutine. The new value takes effect at the next call
+// to Stream.
+func (r *Router) SetMatrix(m Matrix) {
+	r.matrix.Set(m)
+}
+
+// matrixParam is like beep.Param, but for a whole Matrix, so that a Stream call never picks up
+// a half-updated one.
+type matrixParam struct {
+	next unsafe.Pointer // *Matrix set by the last Set and not picked up yet
+}
+
+func (p *matrixParam) Set(m Matrix) {
+	atomic.StorePointer(&p.next, unsafe.Pointer(&m))
+}
+
+func (p *matrixParam) Update(m *Matrix) bool {
+	next := atomic.SwapPointer(&p.next, nil)
+	if next == nil {
+		return false
+	}
+	*m = *(*Matrix)(next)
+	return true
+}
@@ -0,0 +1,63 @@
+package effects_test
+
+import (
+	"testing"
+
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/effects"
+)
+
+func TestRouter(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		matrix effects.Matrix
+		want   [2]float64
+	}{
+		{"identity", effects.IdentityMatrix(), [2]float64{1, 0.5}},
+		{"downmix", effects.DownmixMatrix(), [2]float64{0.75, 0.75}},
+		{"swap", effects.SwapMatrix(), [2]float64{0.5, 1}},
+		{"left only", effects.LeftOnlyMatrix(), [2]float64{1, 1}},
+		{"right only", effects.RightOnlyMatrix(), [2]float64{0.5, 0.5}},
+		{"invert", effects.InvertMatrix(), [2]float64{-1, -0.5}},
+	} {
+		router := &effects.Router{Streamer: beeptest.Constant([2]float64{1, 0.5}, 1), Matrix: tc.matrix}
+		if got := beeptest.Collect(router)[0]; got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	router := &effects.Router{Streamer: beeptest.Constant([2]float64{1, 0.5}, 10), Matrix: effects.IdentityMatrix(), Smoothing: 4}
+	samples := make([][2]float64, 2)
+	router.Stream(samples)
+	router.SetMatrix(effects.SwapMatrix())
+	out := beeptest.Collect(router)
+	if out[0] != [2]float64{0.875, 0.625} || out[len(out)-1] != [2]float64{0.5, 1} {
+		t.Errorf("unexpected smoothed change of the matrix: %v", out)
+	}
+}
+
+func TestRouterSetMatrixConcurrently(t *testing.T) {
+	router := &effects.Router{Streamer: beeptest.Constant([2]float64{1, 0.5}, 100000), Matrix: effects.IdentityMatrix()}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10000; i++ {
+			router.SetMatrix(effects.SwapMatrix())
+			router.SetMatrix(effects.IdentityMatrix())
+		}
+	}()
+
+	samples := make([][2]float64, 10)
+	for {
+		n, ok := router.Stream(samples)
+		for _, sample := range samples[:n] {
+			if sample != [2]float64{1, 0.5} && sample != [2]float64{0.5, 1} {
+				t.Fatalf("half-updated matrix applied: %v", sample)
+			}
+		}
+		if !ok {
+			break
+		}
+	}
+	<-done
+}
@@ -0,0 +1,49 @@
+package effects
+
+// smoother interpolates a parameter linearly toward its target, one sample at a time, to avoid
+// the zipper noise caused by jumps of the parameter at block boundaries.
+type smoother struct {
+	value  float64
+	target float64
+	step   float64
+	left   int // number of samples left until the value reaches the target
+	init   bool
+}
+
+// set sets the target of the parameter. If it differs from the current target, the value moves to
+// it over length samples, starting from the current value. The first call sets the value
+// immediately.
+func (s *smoother) set(target float64, length int) {
+	if !s.init {
+		s.value, s.target, s.init = target, target, true
+		return
+	}
+	if target == s.target {
+		return
+	}
+	s.target = target
+	if length <= 0 {
+		s.value, s.left = target, 0
+		return
+	}
+	s.step = (target - s.value) / float64(length)
+	s.left = length
+}
+
+// next returns the value for the next sample.
+func (s *smoother) next() float64 {
+	if s.left > 0 {
+		s.left--
+		if s.left == 0 {
+			s.value = s.target
+		} else {
+			s.value += s.step
+		}
+	}
+	return s.value
+}
+
+// steady reports whether the value reached the target.
+func (s *smoother) steady() bool {
+	return s.left == 0
+}
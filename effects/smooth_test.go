@@ -0,0 +1,25 @@
+package effects
+
+import "testing"
+
+func TestSmoother(t *testing.T) {
+	var s smoother
+	s.set(1, 4)
+	if s.next() != 1 || !s.steady() {
+		t.Fatal("expected the first value to be set immediately")
+	}
+	s.set(0, 4)
+	want := []float64{0.75, 0.5, 0.25, 0, 0}
+	for i, w := range want {
+		if got := s.next(); got != w {
+			t.Errorf("sample %d: expected %v, got %v", i, w, got)
+		}
+	}
+	if !s.steady() {
+		t.Error("expected the ramp to finish")
+	}
+	s.set(1, 0)
+	if s.next() != 1 {
+		t.Error("expected a jump without smoothing")
+	}
+}
@@ -10,30 +10,50 @@ import (
 // volume is roughly logarithmic to gain and thus the natural way to adjust volume is exponential.
 //
 // Natural Base for the exponentiation is somewhere around 2. In order to adjust volume along
-// decibells, pick 10 as the Base and set Volume to dB/10. However, adjusting volume along decibells
-// is nowhere as natural as with bases around 2.
+// decibels, pick 10 as the Base and set Volume to dB/20, or use SetDB, which works with any Base.
+// For plain decibel gain, see Fader.
 //
 // Volume of 0 means no change. Negative Volume will decrease the perceived volume and positive will
 // increase it.
 //
 // With exponential gain it's impossible to achieve the zero volume. When Silent field is set to
 // true, the output is muted.
+//
+// Smoothing is the number of samples over which changes of Volume and Silent are spread to avoid
+// zipper noise. With a non-zero Smoothing, setting Silent fades the output out instead of cutting
+// it. Zero means the changes are applied at once.
+//
+// Changing the fields of a playing Volume requires locking the speaker. SetVolume and SetSilent
+// change them from any goroutine without locking.
 type Volume struct {
 	Streamer beep.Streamer
 	Base     float64
 	Volume   float64
 	Silent   bool
+
+	Smoothing int // in samples, e.g. sr.N(10 * time.Millisecond)
+
+	volume param
+	silent beep.Flag
+	mute   smoother
 }
 
 // Stream streams the wrapped Streamer with volume adjusted according to Base, Volume and Silent
 // fields.
 func (v *Volume) Stream(samples [][2]float64) (n int, ok bool) {
+	v.silent.Update(&v.Silent)
 	n, ok = v.Streamer.Stream(samples)
-	gain := 0.0
-	if !v.Silent {
-		gain = math.Pow(v.Base, v.Volume)
+	mute := 1.0
+	if v.Silent {
+		mute = 0
 	}
+	v.mute.set(mute, v.Smoothing)
+
+	gains := v.volume.values(&v.Volume, n, v.Smoothing, func(volume float64) float64 {
+		return math.Pow(v.Base, volume)
+	})
 	for i := range samples[:n] {
+		gain := gains[i] * v.mute.next()
 		samples[i][0] *= gain
 		samples[i][1] *= gain
 	}
@@ -44,3 +64,31 @@ func (v *Volume) Stream(samples [][2]float64) (n int, ok bool) {
 func (v *Volume) Err() error {
 	return v.Streamer.Err()
 }
+
+// SetVolume sets the Volume field from any goroutine. The new value takes effect at the next call
+// to Stream.
+func (v *Volume) SetVolume(volume float64) {
+	v.volume.Set(volume)
+}
+
+// SetSilent sets the Silent field from any goroutine. The new value takes effect at the next call
+// to Stream.
+func (v *Volume) SetSilent(silent bool) {
+	v.silent.Set(silent)
+}
+
+// AutomateVolume binds Volume to a, see Automation.
+func (v *Volume) AutomateVolume(a Automation) {
+	v.volume.Automate(a)
+}
+
+// DB returns the gain of the Volume in decibels, ignoring Silent.
+func (v *Volume) DB() float64 {
+	return beep.GainToDB(math.Pow(v.Base, v.Volume))
+}
+
+// SetDB sets Volume, so that the gain is db decibels, from any goroutine like SetVolume. Base must
+// be positive and other than 1. Negative infinity sets the gain to 0.
+func (v *Volume) SetDB(db float64) {
+	v.SetVolume(db / beep.GainToDB(v.Base))
+}
@@ -0,0 +1,53 @@
+package effects
+
+import "github.com/faiface/beep"
+
+// Width changes the stereo width of the wrapped Streamer by scaling its side component. The Width
+// field value of 0 means mono, 1 changes nothing and values above 1 widen the stereo image.
+//
+// Smoothing is the number of samples over which changes of Width are spread to avoid zipper
+// noise. Zero means the changes are applied at once.
+//
+// Changing Width of a playing Width requires locking the speaker, SetWidth changes it from any
+// goroutine without locking.
+type Width struct {
+	Streamer beep.Streamer
+	Width    float64
+
+	Smoothing int // in samples, e.g. sr.N(10 * time.Millisecond)
+
+	width param
+}
+
+// Stream streams the wrapped Streamer with the stereo width set by Width.
+func (w *Width) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = w.Streamer.Stream(samples)
+	widths := w.width.values(&w.Width, n, w.Smoothing, nil)
+	for i := range samples[:n] {
+		samples[i] = widen(samples[i], widths[i])
+	}
+	return n, ok
+}
+
+// widen scales the side component of a single sample by width.
+func widen(sample [2]float64, width float64) [2]float64 {
+	ms := encodeMidSide(sample)
+	ms[1] *= width
+	return decodeMidSide(ms)
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (w *Width) Err() error {
+	return w.Streamer.Err()
+}
+
+// SetWidth sets the Width field from any goroutine. The new value takes effect at the next call to
+// Stream.
+func (w *Width) SetWidth(width float64) {
+	w.width.Set(width)
+}
+
+// AutomateWidth binds Width to a, see Automation.
+func (w *Width) AutomateWidth(a Automation) {
+	w.width.Automate(a)
+}
@@ -31,7 +31,7 @@ type audioPanel struct {
 func newAudioPanel(sampleRate beep.SampleRate, streamer beep.StreamSeeker) *audioPanel {
 	ctrl := &beep.Ctrl{Streamer: beep.Loop(-1, streamer)}
 	resampler := beep.ResampleRatio(4, 1, ctrl)
-	volume := &effects.Volume{Streamer: resampler, Base: 2}
+	volume := &effects.Volume{Streamer: resampler, Base: 2, Smoothing: sampleRate.N(time.Second / 50)}
 	return &audioPanel{sampleRate, streamer, ctrl, resampler, volume}
 }
 
@@ -0,0 +1,65 @@
+package beep
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// Param passes a new value of a float64 parameter to a playing Streamer from any goroutine,
+// without locking the speaker. The Streamer picks the value up by calling Update at the start of
+// its next Stream call.
+//
+// The zero value of Param is ready to use. A Param must not be copied after first use.
+type Param struct {
+	next unsafe.Pointer // *float64 set by the last Set and not picked up yet
+}
+
+// Set sets the new value of the parameter. It may be called from any goroutine.
+func (p *Param) Set(v float64) {
+	atomic.StorePointer(&p.next, unsafe.Pointer(&v))
+}
+
+// Update stores the value set by the last call to Set into *v, if Set was called since the last
+// Update, and reports whether it did. It's meant to be called by the Streamer owning the
+// parameter.
+func (p *Param) Update(v *float64) bool {
+	next := atomic.SwapPointer(&p.next, nil)
+	if next == nil {
+		return false
+	}
+	*v = *(*float64)(next)
+	return true
+}
+
+// Flag is like Param, but for bool parameters.
+//
+// The zero value of Flag is ready to use. A Flag must not be copied after first use.
+type Flag struct {
+	next unsafe.Pointer // *bool set by the last Set and not picked up yet
+}
+
+var (
+	flagFalse = false
+	flagTrue  = true
+)
+
+// Set sets the new value of the parameter. It may be called from any goroutine.
+func (f *Flag) Set(v bool) {
+	next := &flagFalse
+	if v {
+		next = &flagTrue
+	}
+	atomic.StorePointer(&f.next, unsafe.Pointer(next))
+}
+
+// Update stores the value set by the last call to Set into *v, if Set was called since the last
+// Update, and reports whether it did. It's meant to be called by the Streamer owning the
+// parameter.
+func (f *Flag) Update(v *bool) bool {
+	next := atomic.SwapPointer(&f.next, nil)
+	if next == nil {
+		return false
+	}
+	*v = *(*bool)(next)
+	return true
+}
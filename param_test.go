@@ -0,0 +1,56 @@
+package beep_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+)
+
+func TestParam(t *testing.T) {
+	var p beep.Param
+	v := 1.0
+	if p.Update(&v) || v != 1 {
+		t.Error("expected no update before Set")
+	}
+	p.Set(2)
+	p.Set(3)
+	if !p.Update(&v) || v != 3 {
+		t.Errorf("expected the last set value, got %v", v)
+	}
+	if p.Update(&v) {
+		t.Error("expected the value to be picked up only once")
+	}
+
+	var f beep.Flag
+	b := false
+	f.Set(true)
+	if !f.Update(&b) || !b {
+		t.Error("expected the flag to be set")
+	}
+}
+
+func TestCtrlSetPaused(t *testing.T) {
+	ctrl := &beep.Ctrl{Streamer: beeptest.Constant([2]float64{1, 1}, 100000)}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			ctrl.SetPaused(i%2 == 0)
+		}
+	}()
+	samples := make([][2]float64, 10)
+	for i := 0; i < 100; i++ {
+		ctrl.Stream(samples)
+	}
+	wg.Wait()
+
+	ctrl.SetPaused(true)
+	ctrl.Stream(samples)
+	if !ctrl.Paused || samples[0] != [2]float64{} {
+		t.Error("expected the Ctrl to be paused at the next Stream call")
+	}
+}
@@ -0,0 +1,519 @@
+// Package playlist implements gapless playback of a queue of audio sources.
+package playlist
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/flac"
+	"github.com/faiface/beep/mp3"
+	"github.com/faiface/beep/vorbis"
+	"github.com/faiface/beep/wav"
+	"github.com/pkg/errors"
+)
+
+// Opener opens an audio source for playback. Each call must return a new StreamSeekCloser, which
+// will be closed by the Playlist when no longer needed.
+type Opener func() (s beep.StreamSeekCloser, format beep.Format, err error)
+
+// Item is a single entry of a Playlist.
+type Item struct {
+	// Name identifies the Item, e.g. the path to the file.
+	Name string
+
+	// Open opens the Item for playback.
+	Open Opener
+}
+
+// File returns an Item which decodes the file at path. The decoder is chosen by the file
+// extension, WAV, MP3, OGG (Vorbis) and FLAC files are supported.
+func File(path string) Item {
+	return Item{
+		Name: path,
+		Open: func() (s beep.StreamSeekCloser, format beep.Format, err error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, beep.Format{}, errors.Wrap(err, "playlist")
+			}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".wav":
+				s, format, err = wav.Decode(f)
+			case ".mp3":
+				s, format, err = mp3.Decode(f)
+			case ".ogg":
+				s, format, err = vorbis.Decode(f)
+			case ".flac":
+				s, format, err = flac.Decode(f)
+			default:
+				err = fmt.Errorf("playlist: unsupported file type: %s", path)
+			}
+			if err != nil {
+				f.Close()
+				return nil, beep.Format{}, err
+			}
+			return s, format, nil
+		},
+	}
+}
+
+// Repeat specifies what happens when an Item or the whole Playlist finishes playing.
+type Repeat int
+
+// Repeat modes of a Playlist.
+const (
+	// RepeatOff stops the playback after the last Item.
+	RepeatOff Repeat = iota
+
+	// RepeatAll continues with the first Item after the last one. If none of the Items streams
+	// any samples during a whole pass, the Playlist ends.
+	RepeatAll
+
+	// RepeatOne plays the current Item over and over. Next and Previous still switch Items. An
+	// Item which streams no samples is not repeated, the Playlist moves on as with RepeatOff.
+	RepeatOne
+)
+
+// Options configure a Playlist.
+type Options struct {
+	// SampleRate is the sample rate the Playlist streams at. Items with a different sample rate
+	// are resampled.
+	SampleRate beep.SampleRate
+
+	// Quality is the quality of the resampling, see beep.Resample. Defaults to 4.
+	Quality int
+
+	// Preload is the duration of the beginning of the next Item decoded ahead of time. Defaults
+	// to a quarter of a second.
+	Preload time.Duration
+
+	// Shuffle and Repeat set the initial playing order.
+	Shuffle bool
+	Repeat  Repeat
+
+	// Seed seeds the random generator used for shuffling.
+	Seed int64
+
+	// OnError, if not nil, is called when an Item fails to open or fails during playback. The
+	// failed Item is skipped. It's called from Stream, so the speaker is locked during the call.
+	OnError func(item Item, err error)
+}
+
+// Playlist is a Streamer which plays a queue of Items one after another without gaps.
+//
+// While an Item is playing, the next one is opened and its beginning decoded on a background
+// goroutine, so the transition doesn't have to wait for the I/O.
+//
+// Playlist never returns an error through Err, failing Items are skipped and reported through
+// Options.OnError instead.
+//
+// If you're playing a Playlist through the speaker, you need to lock the speaker when calling
+// any of its methods, just like with beep.Ctrl.
+type Playlist struct {
+	opts  Options
+	items []Item
+	order []int // order of playback, indices into items
+	idx   int   // index into order of the current Item
+	rng   *rand.Rand
+
+	cur     *track   // the currently playing Item, nil while loading or when ended
+	loading *pending // the Item being switched to, becomes cur when loaded
+	next    *pending // the Item which will be played after cur
+	ended   bool
+
+	streamed bool // whether cur streamed any samples since it was (re)started
+	empty    int  // number of Items in a row which failed or streamed no samples
+}
+
+// New creates a Playlist of the provided Items. The playback starts at the first Item (or at a
+// random one, if shuffling).
+func New(opts Options, items ...Item) *Playlist {
+	if opts.Quality == 0 {
+		opts.Quality = 4
+	}
+	if opts.Preload == 0 {
+		opts.Preload = time.Second / 4
+	}
+	p := &Playlist{
+		opts: opts,
+		rng:  rand.New(rand.NewSource(opts.Seed)),
+	}
+	p.items = append(p.items, items...)
+	for i := range p.items {
+		p.order = append(p.order, i)
+	}
+	if opts.Shuffle {
+		p.rng.Shuffle(len(p.order), func(i, j int) {
+			p.order[i], p.order[j] = p.order[j], p.order[i]
+		})
+	}
+	if len(p.order) == 0 {
+		p.ended = true
+		return p
+	}
+	p.loading = p.start(p.order[0], true)
+	p.preloadNext()
+	return p
+}
+
+// Len returns the number of Items in the Playlist.
+func (p *Playlist) Len() int {
+	return len(p.items)
+}
+
+// Item returns the i-th Item of the Playlist.
+func (p *Playlist) Item(i int) Item {
+	return p.items[i]
+}
+
+// Add appends Items to the end of the Playlist. When shuffling, they are inserted at random
+// positions after the current Item. If the Playlist has ended, it continues with the first added
+// Item.
+func (p *Playlist) Add(items ...Item) {
+	if len(items) == 0 {
+		return
+	}
+	first := len(p.order)
+	for _, item := range items {
+		p.items = append(p.items, item)
+		at := len(p.order)
+		if p.opts.Shuffle && !p.ended && at > p.idx+1 {
+			at = p.idx + 1 + p.rng.Intn(at-p.idx)
+		}
+		p.order = append(p.order, 0)
+		copy(p.order[at+1:], p.order[at:])
+		p.order[at] = len(p.items) - 1
+	}
+	if p.ended {
+		p.ended = false
+		p.switchTo(first, true)
+		return
+	}
+	p.preloadNext()
+}
+
+// Current returns the index of the currently playing Item, or -1 if the Playlist ended.
+func (p *Playlist) Current() int {
+	if p.ended {
+		return -1
+	}
+	return p.order[p.idx]
+}
+
+// Position returns the position within the current Item in samples at the Playlist's sample
+// rate. It's 0 while the Item is loading.
+func (p *Playlist) Position() int {
+	if p.cur == nil {
+		return 0
+	}
+	return p.cur.position(p.opts.SampleRate)
+}
+
+// ItemLen returns the length of the current Item in samples at the Playlist's sample rate. It's 0
+// while the Item is loading.
+func (p *Playlist) ItemLen() int {
+	if p.cur == nil {
+		return 0
+	}
+	return p.cur.len(p.opts.SampleRate)
+}
+
+// Next switches to the next Item. If the current Item is the last one and the Playlist is not
+// repeating all, the Playlist ends.
+func (p *Playlist) Next() {
+	if p.ended {
+		return
+	}
+	j := p.following(p.idx, false)
+	if j < 0 {
+		p.Close()
+		return
+	}
+	p.switchTo(j, false)
+}
+
+// Previous switches to the previous Item. If the current Item is the first one, it either
+// continues with the last Item, when repeating all, or restarts the first one.
+func (p *Playlist) Previous() {
+	if len(p.order) == 0 {
+		return
+	}
+	j := p.idx - 1
+	if j < 0 {
+		j = 0
+		if p.opts.Repeat == RepeatAll {
+			j = len(p.order) - 1
+		}
+	}
+	p.ended = false
+	p.switchTo(j, false)
+}
+
+// Jump switches to the i-th Item of the Playlist.
+func (p *Playlist) Jump(i int) {
+	if i < 0 || len(p.items) <= i {
+		panic(fmt.Errorf("playlist: jump index %d out of range [0, %d)", i, len(p.items)))
+	}
+	for j := range p.order {
+		if p.order[j] == i {
+			p.ended = false
+			p.switchTo(j, false)
+			return
+		}
+	}
+}
+
+// Shuffle returns whether the Playlist plays in random order.
+func (p *Playlist) Shuffle() bool {
+	return p.opts.Shuffle
+}
+
+// SetShuffle turns shuffling on or off. Turning shuffling on randomizes the order of all of the
+// Items except for the current one, which keeps playing.
+func (p *Playlist) SetShuffle(shuffle bool) {
+	p.opts.Shuffle = shuffle
+	if len(p.order) == 0 {
+		return
+	}
+	current := p.order[p.idx]
+	for i := range p.order {
+		p.order[i] = i
+	}
+	if shuffle {
+		p.order[0], p.order[current] = p.order[current], p.order[0]
+		rest := p.order[1:]
+		p.rng.Shuffle(len(rest), func(i, j int) {
+			rest[i], rest[j] = rest[j], rest[i]
+		})
+		p.idx = 0
+	} else {
+		p.idx = current
+	}
+	p.preloadNext()
+}
+
+// Repeat returns the current repeat mode.
+func (p *Playlist) Repeat() Repeat {
+	return p.opts.Repeat
+}
+
+// SetRepeat sets the repeat mode.
+func (p *Playlist) SetRepeat(repeat Repeat) {
+	p.opts.Repeat = repeat
+	p.preloadNext()
+}
+
+// Stream streams the current Item and continues with the following ones without gaps. While an
+// Item selected by Next, Previous or Jump is loading, Playlist streams silence.
+func (p *Playlist) Stream(samples [][2]float64) (n int, ok bool) {
+	for len(samples) > 0 {
+		if p.cur == nil {
+			if p.loading == nil {
+				break
+			}
+			t := p.loading.take()
+			if t == nil {
+				// manual switch still loading, don't block the playback
+				for i := range samples {
+					samples[i] = [2]float64{}
+				}
+				return n + len(samples), true
+			}
+			p.loading = nil
+			if t.loadErr != nil {
+				p.fail(t)
+				p.advance(false)
+				continue
+			}
+			p.cur = t
+			p.streamed = false
+		}
+
+		sn, sok := p.cur.r.Stream(samples)
+		samples = samples[sn:]
+		n += sn
+		if sn > 0 {
+			p.streamed = true
+			p.empty = 0
+		}
+		if !sok {
+			failed := p.cur.error() != nil
+			if failed {
+				p.fail(p.cur)
+			} else if !p.streamed {
+				p.empty++
+			}
+			// an Item without any samples is never repeated, that would loop forever
+			p.advance(!failed && p.streamed && p.opts.Repeat == RepeatOne)
+		}
+	}
+	if n == 0 && p.ended {
+		return 0, false
+	}
+	return n, true
+}
+
+// Err always returns nil, failing Items are reported through Options.OnError.
+func (p *Playlist) Err() error {
+	return nil
+}
+
+// Close stops the playback and releases all of the opened Items.
+func (p *Playlist) Close() error {
+	var err error
+	if p.cur != nil {
+		err = p.cur.close()
+		p.cur = nil
+	}
+	p.loading.discard()
+	p.next.discard()
+	p.loading, p.next = nil, nil
+	p.ended = true
+	return err
+}
+
+// following returns the index into order of the Item after the one at index i, or -1 if there
+// is none.
+func (p *Playlist) following(i int, natural bool) int {
+	if natural && p.opts.Repeat == RepeatOne {
+		return i
+	}
+	if i+1 < len(p.order) {
+		return i + 1
+	}
+	if p.opts.Repeat == RepeatAll {
+		return 0
+	}
+	return -1
+}
+
+// advance moves on after the current Item finished playing or failed. If replay is true, the
+// current Item is played again.
+func (p *Playlist) advance(replay bool) {
+	if replay && p.cur.rewind(p.opts.SampleRate, p.opts.Quality) == nil {
+		p.streamed = false
+		return
+	}
+	j := p.following(p.idx, false)
+	if p.cur != nil {
+		p.cur.close()
+		p.cur = nil
+	}
+	if j < 0 || p.empty >= len(p.order) {
+		p.Close()
+		return
+	}
+	p.idx = j
+	if p.next != nil && p.next.item == p.order[j] {
+		p.loading, p.next = p.next, nil
+		p.loading.wait = true
+	} else {
+		p.loading = p.start(p.order[j], true)
+	}
+	p.preloadNext()
+}
+
+// switchTo starts playing the Item at index j into order, as a result of a manual switch.
+func (p *Playlist) switchTo(j int, wait bool) {
+	if p.cur != nil {
+		p.cur.close()
+		p.cur = nil
+	}
+	p.loading.discard()
+	p.idx = j
+	if p.next != nil && p.next.item == p.order[j] {
+		p.loading, p.next = p.next, nil
+		p.loading.wait = wait
+	} else {
+		p.loading = p.start(p.order[j], wait)
+	}
+	p.preloadNext()
+}
+
+// preloadNext makes sure the Item which follows the current one is being preloaded.
+func (p *Playlist) preloadNext() {
+	if p.ended {
+		return
+	}
+	item := -1
+	if j := p.following(p.idx, false); j >= 0 {
+		item = p.order[j]
+	}
+	if p.next != nil && p.next.item == item {
+		return
+	}
+	p.next.discard()
+	p.next = nil
+	if item >= 0 {
+		p.next = p.start(item, true)
+	}
+}
+
+func (p *Playlist) fail(t *track) {
+	p.empty++
+	if p.opts.OnError != nil {
+		p.opts.OnError(p.items[t.item], t.error())
+	}
+}
+
+// start opens the i-th Item and decodes its beginning on a new goroutine.
+func (p *Playlist) start(i int, wait bool) *pending {
+	pd := &pending{
+		item: i,
+		ch:   make(chan *track, 1),
+		wait: wait,
+	}
+	var (
+		item    = p.items[i]
+		sr      = p.opts.SampleRate
+		quality = p.opts.Quality
+		preload = p.opts.Preload
+	)
+	go func() {
+		pd.ch <- load(i, item, sr, quality, preload)
+	}()
+	return pd
+}
+
+// pending is an Item being loaded in the background.
+type pending struct {
+	item int
+	ch   chan *track
+	wait bool // whether taking the Item should block until it's loaded
+	t    *track
+}
+
+// take returns the loaded track, or nil if it's not ready and waiting is not allowed.
+func (pd *pending) take() *track {
+	if pd.t != nil {
+		return pd.t
+	}
+	if pd.wait {
+		pd.t = <-pd.ch
+		return pd.t
+	}
+	select {
+	case pd.t = <-pd.ch:
+		return pd.t
+	default:
+		return nil
+	}
+}
+
+// discard closes the Item when it's loaded, without waiting for it.
+func (pd *pending) discard() {
+	if pd == nil {
+		return
+	}
+	if pd.t != nil {
+		pd.t.close()
+		return
+	}
+	go func(ch chan *track) {
+		(<-ch).close()
+	}(pd.ch)
+}
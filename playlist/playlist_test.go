@@ -0,0 +1,191 @@
+package playlist_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/playlist"
+)
+
+type nopCloser struct {
+	beep.StreamSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// item returns an Item which streams the data at 44100 samples per second.
+func item(name string, data [][2]float64) playlist.Item {
+	return playlist.Item{
+		Name: name,
+		Open: func() (beep.StreamSeekCloser, beep.Format, error) {
+			format := beep.Format{SampleRate: 44100, NumChannels: 2, Precision: 2}
+			return nopCloser{beeptest.Data(data)}, format, nil
+		},
+	}
+}
+
+func items(lengths ...int) ([]playlist.Item, [][][2]float64) {
+	var (
+		items []playlist.Item
+		data  [][][2]float64
+	)
+	for i, length := range lengths {
+		d := beeptest.Collect(beeptest.Noise(int64(i), length))
+		items = append(items, item(string(rune('a'+i)), d))
+		data = append(data, d)
+	}
+	return items, data
+}
+
+func concat(data ...[][2]float64) [][2]float64 {
+	var result [][2]float64
+	for _, d := range data {
+		result = append(result, d...)
+	}
+	return result
+}
+
+func TestPlaylistGapless(t *testing.T) {
+	items, data := items(30000, 1, 5000, 12345)
+	p := playlist.New(playlist.Options{SampleRate: 44100}, items...)
+
+	got := beeptest.Collect(p)
+	if !reflect.DeepEqual(concat(data...), got) {
+		t.Errorf("Playlist did not stream the items gaplessly (got %d samples)", len(got))
+	}
+	if p.Current() != -1 {
+		t.Errorf("expected the Playlist to end, current is %d", p.Current())
+	}
+}
+
+func TestPlaylistNavigation(t *testing.T) {
+	items, data := items(1000, 1000, 1000)
+	p := playlist.New(playlist.Options{SampleRate: 44100}, items...)
+
+	buf := make([][2]float64, 100)
+	p.Stream(buf)
+	if p.Current() != 0 || p.Position() != 100 || p.ItemLen() != 1000 {
+		t.Fatalf("unexpected state: current %d, position %d, len %d", p.Current(), p.Position(), p.ItemLen())
+	}
+
+	p.Jump(2)
+	if p.Current() != 2 {
+		t.Fatalf("expected current 2 after Jump, got %d", p.Current())
+	}
+	got := beeptest.Collect(p)
+	if len(got) < len(data[2]) || !reflect.DeepEqual(data[2], got[len(got)-len(data[2]):]) {
+		t.Error("Jump did not play the selected item")
+	}
+
+	p.Previous()
+	if p.Current() != 1 {
+		t.Errorf("expected current 1 after Previous, got %d", p.Current())
+	}
+	p.Next()
+	p.Next()
+	if p.Current() != -1 {
+		t.Errorf("expected the Playlist to end after the last item, current is %d", p.Current())
+	}
+}
+
+func TestPlaylistRepeat(t *testing.T) {
+	items, data := items(700, 300)
+
+	p := playlist.New(playlist.Options{SampleRate: 44100, Repeat: playlist.RepeatAll}, items...)
+	want := concat(data[0], data[1], data[0], data[1], data[0])
+	if got := beeptest.CollectN(p, len(want)); !reflect.DeepEqual(want, got) {
+		t.Error("RepeatAll did not repeat the playlist")
+	}
+
+	p = playlist.New(playlist.Options{SampleRate: 44100, Repeat: playlist.RepeatOne}, items...)
+	want = concat(data[0], data[0], data[0])
+	if got := beeptest.CollectN(p, len(want)); !reflect.DeepEqual(want, got) {
+		t.Error("RepeatOne did not repeat the item")
+	}
+}
+
+func TestPlaylistRepeatEmpty(t *testing.T) {
+	empty, _ := items(0, 0)
+	p := playlist.New(playlist.Options{SampleRate: 44100, Repeat: playlist.RepeatAll}, empty...)
+	if n, ok := p.Stream(make([][2]float64, 10)); n != 0 || ok {
+		t.Errorf("RepeatAll of empty items did not drain: %v, %v", n, ok)
+	}
+
+	p = playlist.New(playlist.Options{SampleRate: 44100, Repeat: playlist.RepeatOne}, empty[0])
+	if n, ok := p.Stream(make([][2]float64, 10)); n != 0 || ok {
+		t.Errorf("RepeatOne of an empty item did not drain: %v, %v", n, ok)
+	}
+
+	items, data := items(0, 300, 0)
+	p = playlist.New(playlist.Options{SampleRate: 44100, Repeat: playlist.RepeatAll}, items...)
+	want := concat(data[1], data[1], data[1])
+	if got := beeptest.CollectN(p, len(want)); !reflect.DeepEqual(want, got) {
+		t.Error("RepeatAll did not skip the empty items")
+	}
+}
+
+func TestPlaylistShuffle(t *testing.T) {
+	items, _ := items(10, 10, 10, 10, 10, 10, 10, 10)
+	p := playlist.New(playlist.Options{SampleRate: 44100, Seed: 42}, items...)
+
+	p.SetShuffle(true)
+	if p.Current() != 0 {
+		t.Fatalf("shuffling changed the current item to %d", p.Current())
+	}
+	seen := map[int]bool{}
+	for p.Current() >= 0 {
+		if seen[p.Current()] {
+			t.Fatalf("item %d played twice", p.Current())
+		}
+		seen[p.Current()] = true
+		p.Next()
+	}
+	if len(seen) != len(items) {
+		t.Errorf("expected all %d items to play, played %d", len(items), len(seen))
+	}
+}
+
+func TestPlaylistSkipsFailing(t *testing.T) {
+	items, data := items(500, 500)
+	broken := playlist.Item{
+		Name: "broken",
+		Open: func() (beep.StreamSeekCloser, beep.Format, error) {
+			return nil, beep.Format{}, errors.New("no such file")
+		},
+	}
+
+	var failed []string
+	p := playlist.New(playlist.Options{
+		SampleRate: 44100,
+		OnError:    func(item playlist.Item, err error) { failed = append(failed, item.Name) },
+	}, items[0], broken, items[1])
+
+	got := beeptest.Collect(p)
+	if !reflect.DeepEqual(concat(data...), got) {
+		t.Error("Playlist did not skip the failing item")
+	}
+	if !reflect.DeepEqual(failed, []string{"broken"}) {
+		t.Errorf("unexpected failed items: %v", failed)
+	}
+
+	p = playlist.New(playlist.Options{SampleRate: 44100, Repeat: playlist.RepeatAll}, broken, broken)
+	if n, ok := p.Stream(make([][2]float64, 10)); n != 0 || ok {
+		t.Errorf("Playlist of failing items did not drain: %v, %v", n, ok)
+	}
+}
+
+func TestPlaylistResample(t *testing.T) {
+	items, _ := items(44100)
+	p := playlist.New(playlist.Options{SampleRate: 22050}, items...)
+	beeptest.Check(t, p, -1)
+
+	p = playlist.New(playlist.Options{SampleRate: 22050}, items...)
+	if got := beeptest.Collect(p); len(got) < 22000 || len(got) > 22100 {
+		t.Errorf("expected about 22050 resampled samples, got %d", len(got))
+	}
+}
@@ -0,0 +1,105 @@
+package playlist
+
+import (
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// track is an opened Item together with its pre-decoded beginning.
+type track struct {
+	item    int
+	s       beep.StreamSeekCloser
+	format  beep.Format
+	head    [][2]float64 // pre-decoded samples which were not streamed yet
+	r       beep.Streamer
+	loadErr error
+}
+
+// load opens the i-th Item and decodes preload worth of its beginning.
+func load(i int, item Item, sr beep.SampleRate, quality int, preload time.Duration) *track {
+	s, format, err := item.Open()
+	if err != nil {
+		return &track{item: i, loadErr: err}
+	}
+	t := &track{
+		item:   i,
+		s:      s,
+		format: format,
+		head:   make([][2]float64, format.SampleRate.N(preload)),
+	}
+	n := 0
+	for n < len(t.head) {
+		sn, ok := s.Stream(t.head[n:])
+		n += sn
+		if !ok {
+			break
+		}
+	}
+	t.head = t.head[:n]
+	if err := s.Err(); err != nil {
+		s.Close()
+		return &track{item: i, loadErr: err}
+	}
+	t.resample(sr, quality)
+	return t
+}
+
+func (t *track) resample(sr beep.SampleRate, quality int) {
+	t.r = beep.Streamer(t)
+	if t.format.SampleRate != sr {
+		t.r = beep.Resample(quality, t.format.SampleRate, sr, t)
+	}
+}
+
+// Stream streams the pre-decoded beginning followed by the rest of the Item at the Item's
+// sample rate.
+func (t *track) Stream(samples [][2]float64) (n int, ok bool) {
+	if len(t.head) > 0 {
+		n = copy(samples, t.head)
+		t.head = t.head[n:]
+		samples = samples[n:]
+	}
+	if len(samples) == 0 {
+		return n, true
+	}
+	sn, sok := t.s.Stream(samples)
+	return n + sn, n > 0 || sok
+}
+
+func (t *track) Err() error {
+	return t.s.Err()
+}
+
+func (t *track) error() error {
+	if t.loadErr != nil {
+		return t.loadErr
+	}
+	return t.s.Err()
+}
+
+func (t *track) position(sr beep.SampleRate) int {
+	p := t.s.Position() - len(t.head)
+	return int(int64(p) * int64(sr) / int64(t.format.SampleRate))
+}
+
+func (t *track) len(sr beep.SampleRate) int {
+	return int(int64(t.s.Len()) * int64(sr) / int64(t.format.SampleRate))
+}
+
+// rewind restarts the track from the beginning.
+func (t *track) rewind(sr beep.SampleRate, quality int) error {
+	if err := t.s.Seek(0); err != nil {
+		return err
+	}
+	t.head = nil
+	t.resample(sr, quality)
+	return nil
+}
+
+func (t *track) close() error {
+	if t == nil || t.s == nil {
+		return nil
+	}
+	return t.s.Close()
+}
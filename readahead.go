@@ -0,0 +1,242 @@
+package beep
+
+import (
+	"errors"
+	"sync"
+)
+
+// ReadAhead returns a ReadAheader which streams s from its own goroutine through a ring buffer of
+// size samples. This moves the decoding and I/O of s out of the caller, which is useful with
+// decoders played through the speaker, since the speaker is locked while streaming.
+//
+//   streamer, format, err := mp3.Decode(f)
+//   // ...
+//   ra := beep.ReadAhead(streamer, format.SampleRate.N(time.Second))
+//   defer ra.Close()
+//   speaker.Play(ra)
+//
+// ReadAhead fills the buffer before returning. If the buffer runs empty during streaming, the
+// ReadAheader streams silence instead of blocking and counts an underrun.
+//
+// The source Streamer must not be used directly after passing it to ReadAhead.
+//
+// ReadAhead propagates errors from s once all of the samples streamed before the error were
+// streamed.
+func ReadAhead(s Streamer, size int) *ReadAheader {
+	if size <= 0 {
+		size = 512
+	}
+	ra := &ReadAheader{
+		s:    s,
+		ring: make([][2]float64, size),
+		exit: make(chan struct{}),
+	}
+	ra.cond = sync.NewCond(&ra.mu)
+	if ss, ok := s.(StreamSeeker); ok {
+		ra.ss = ss
+		ra.len = ss.Len()
+		ra.pos = ss.Position()
+	}
+	go ra.run()
+
+	ra.mu.Lock()
+	for !ra.done && ra.count < len(ra.ring) {
+		ra.cond.Wait()
+	}
+	ra.mu.Unlock()
+
+	return ra
+}
+
+// ReadAheader is a Streamer created by ReadAhead. If the source Streamer is a StreamSeeker, so is
+// the ReadAheader, otherwise its Seek method returns an error.
+type ReadAheader struct {
+	s    Streamer
+	ss   StreamSeeker // s, if it's a StreamSeeker
+	len  int          // s.Len(), cached to avoid racing with the reading goroutine
+	exit chan struct{}
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	ring      [][2]float64
+	start     int   // index of the first buffered sample in ring
+	count     int   // number of buffered samples
+	pos       int   // position of the first buffered sample in s
+	done      bool  // s is drained
+	err       error // error of s, reported once the buffer is drained
+	closed    bool
+	seeking   bool // a seek to seekTo was requested and is not finished yet
+	seekTo    int
+	seekErr   error
+	underruns int
+}
+
+func (ra *ReadAheader) run() {
+	defer close(ra.exit)
+
+	chunk := make([][2]float64, 512)
+	if len(chunk) > len(ra.ring) {
+		chunk = chunk[:len(ra.ring)]
+	}
+
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	for {
+		for !ra.closed && !ra.seeking && (ra.done || ra.count == len(ra.ring)) {
+			ra.cond.Wait()
+		}
+
+		if ra.seeking {
+			p := ra.seekTo
+			ra.mu.Unlock()
+			err := ra.ss.Seek(p)
+			ra.mu.Lock()
+			if err == nil {
+				ra.start, ra.count, ra.pos = 0, 0, p
+				ra.done, ra.err = false, nil
+			}
+			ra.seekErr = err
+			ra.seeking = false
+			ra.cond.Broadcast()
+			continue
+		}
+		if ra.closed {
+			return
+		}
+
+		toRead := len(ra.ring) - ra.count
+		if toRead > len(chunk) {
+			toRead = len(chunk)
+		}
+		ra.mu.Unlock()
+		n, ok := ra.s.Stream(chunk[:toRead])
+		err := ra.s.Err()
+		ra.mu.Lock()
+
+		if ra.seeking {
+			// the samples were read from before the seek position, throw them away
+			continue
+		}
+		for _, sample := range chunk[:n] {
+			ra.ring[(ra.start+ra.count)%len(ra.ring)] = sample
+			ra.count++
+		}
+		// streaming less than requested also means s is drained
+		if !ok || err != nil || n < toRead {
+			ra.done, ra.err = true, err
+		}
+		ra.cond.Broadcast()
+	}
+}
+
+// Stream streams the buffered samples. If the buffer doesn't contain enough samples and the
+// source is not drained, the rest is filled with silence.
+func (ra *ReadAheader) Stream(samples [][2]float64) (n int, ok bool) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	if ra.closed || (ra.done && ra.count == 0) {
+		return 0, false
+	}
+
+	for n < len(samples) && ra.count > 0 {
+		samples[n] = ra.ring[ra.start]
+		ra.start = (ra.start + 1) % len(ra.ring)
+		ra.count--
+		n++
+	}
+	ra.pos += n
+	ra.cond.Broadcast()
+
+	if n < len(samples) && !ra.done {
+		ra.underruns++
+		for i := range samples[n:] {
+			samples[n+i] = [2]float64{}
+		}
+		n = len(samples)
+	}
+	return n, true
+}
+
+// Err propagates the source Streamer's errors.
+func (ra *ReadAheader) Err() error {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	if ra.count > 0 {
+		return nil
+	}
+	return ra.err
+}
+
+// Underruns returns the number of times Stream had to fill in silence, because the buffer ran
+// empty.
+func (ra *ReadAheader) Underruns() int {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	return ra.underruns
+}
+
+// Buffered returns the number of samples currently in the buffer.
+func (ra *ReadAheader) Buffered() int {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	return ra.count
+}
+
+// Len returns the length of the source Streamer, or 0 if it's not a StreamSeeker.
+func (ra *ReadAheader) Len() int {
+	return ra.len
+}
+
+// Position returns the position of the next streamed sample in the source Streamer. The source
+// Streamer itself is ahead by the number of buffered samples.
+func (ra *ReadAheader) Position() int {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	return ra.pos
+}
+
+// Seek flushes the buffer and seeks the source Streamer to the provided position. The buffer
+// refills in the background, so Stream may underrun shortly after seeking.
+func (ra *ReadAheader) Seek(p int) error {
+	if ra.ss == nil {
+		return errors.New("read ahead: source is not a StreamSeeker")
+	}
+
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+
+	if ra.closed {
+		return errors.New("read ahead: seek after close")
+	}
+	for ra.seeking {
+		ra.cond.Wait()
+	}
+	ra.seeking, ra.seekTo = true, p
+	ra.cond.Broadcast()
+	for ra.seeking {
+		ra.cond.Wait()
+	}
+	return ra.seekErr
+}
+
+// Close stops the reading goroutine and closes the source Streamer, if it's a StreamCloser. The
+// ReadAheader is drained after Close.
+func (ra *ReadAheader) Close() error {
+	ra.mu.Lock()
+	if ra.closed {
+		ra.mu.Unlock()
+		return nil
+	}
+	ra.closed = true
+	ra.cond.Broadcast()
+	ra.mu.Unlock()
+
+	<-ra.exit
+
+	if sc, ok := ra.s.(StreamCloser); ok {
+		return sc.Close()
+	}
+	return nil
+}
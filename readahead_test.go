@@ -0,0 +1,112 @@
+package beep_test
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// collectPatiently drains ra, waiting for the buffer to contain enough samples before each call
+// to Stream, so that the result doesn't depend on the speed of the reading goroutine.
+func collectPatiently(t *testing.T, ra *beep.ReadAheader, bufSize int) [][2]float64 {
+	var (
+		result [][2]float64
+		buf    = make([][2]float64, bufSize)
+	)
+	for ra.Position() < ra.Len() {
+		want := bufSize
+		if want > ra.Len()-ra.Position() {
+			want = ra.Len() - ra.Position()
+		}
+		for deadline := time.Now().Add(time.Second); ra.Buffered() < want && time.Now().Before(deadline); {
+			runtime.Gosched()
+		}
+		n, _ := ra.Stream(buf[:want])
+		result = append(result, buf[:n]...)
+	}
+	if ra.Underruns() > 0 {
+		t.Fatalf("unexpected underruns: %d", ra.Underruns())
+	}
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if _, ok := ra.Stream(buf); !ok {
+			return result
+		}
+	}
+	t.Fatal("ReadAheader did not drain")
+	return nil
+}
+
+func TestReadAhead(t *testing.T) {
+	for _, size := range []int{1, 100, 512, 5000, 100000} {
+		s, data := randomDataStreamer(20000)
+		ra := beep.ReadAhead(s, size)
+
+		bufSize := 1234
+		if bufSize > size {
+			bufSize = size
+		}
+		got := collectPatiently(t, ra, bufSize)
+		ra.Close()
+
+		if !reflect.DeepEqual(data, got) {
+			t.Errorf("ReadAhead with size %d did not stream the source data", size)
+		}
+	}
+}
+
+func TestReadAheadSeek(t *testing.T) {
+	s, data := randomDataStreamer(10000)
+	ra := beep.ReadAhead(s, 1000)
+	defer ra.Close()
+
+	ra.Stream(make([][2]float64, 500))
+	if err := ra.Seek(6000); err != nil {
+		t.Fatal(err)
+	}
+	if ra.Position() != 6000 || ra.Len() != 10000 {
+		t.Fatalf("unexpected position %d and length %d after seek", ra.Position(), ra.Len())
+	}
+
+	got := collectPatiently(t, ra, 700)
+	if !reflect.DeepEqual(data[6000:], got) {
+		t.Error("ReadAhead did not stream from the seek position")
+	}
+}
+
+func TestReadAheadUnderrun(t *testing.T) {
+	release := make(chan struct{})
+	calls := 0
+	s := beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		calls++
+		if calls > 1 {
+			<-release
+			return 0, false
+		}
+		for i := range samples {
+			samples[i] = [2]float64{1, 1}
+		}
+		return len(samples), true
+	})
+
+	ra := beep.ReadAhead(s, 100)
+	buf := make([][2]float64, 200)
+	n, ok := ra.Stream(buf)
+	if n != 200 || !ok {
+		t.Fatalf("expected silence to be filled in, got %v, %v", n, ok)
+	}
+	if buf[99] != [2]float64{1, 1} || buf[100] != [2]float64{} {
+		t.Errorf("unexpected samples around the underrun: %v, %v", buf[99], buf[100])
+	}
+	if ra.Underruns() != 1 {
+		t.Errorf("expected 1 underrun, got %d", ra.Underruns())
+	}
+
+	close(release)
+	ra.Close()
+	if n, ok := ra.Stream(buf); n != 0 || ok {
+		t.Errorf("expected drained after Close, got %v, %v", n, ok)
+	}
+}
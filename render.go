@@ -0,0 +1,145 @@
+package beep
+
+import "context"
+
+// Sink consumes all audio from a Streamer, usually by encoding it somewhere. Encoders can be
+// turned into Sinks with a closure:
+//
+//   sink := func(s beep.Streamer) error {
+//       return wav.Encode(f, s, format)
+//   }
+type Sink func(s Streamer) error
+
+// RenderProgress describes how far Render got.
+type RenderProgress struct {
+	// Done is the number of samples rendered so far.
+	Done int
+
+	// Total is the expected total number of samples, or -1 if it's not known.
+	Total int
+
+	// Estimated is true if Total was not provided through RenderOptions, but estimated from the
+	// Streamer.
+	Estimated bool
+}
+
+// Fraction returns the rendered fraction between 0 and 1, or -1 if the total is unknown.
+func (p RenderProgress) Fraction() float64 {
+	if p.Total < 0 {
+		return -1
+	}
+	if p.Total == 0 || p.Done >= p.Total {
+		return 1
+	}
+	return float64(p.Done) / float64(p.Total)
+}
+
+// RenderOptions configure Render.
+type RenderOptions struct {
+	// BlockSize is the number of samples pulled from the Streamer at once. The context
+	// cancellation is checked and the progress reported once per block. Defaults to 512.
+	BlockSize int
+
+	// Length is the total number of samples the Streamer is known to stream, it's only used for
+	// progress reporting. If zero, the length is estimated from the Streamer if it's a
+	// StreamSeeker, otherwise it's unknown.
+	Length int
+
+	// Progress, if not nil, is called after each rendered block and once more when the
+	// rendering finishes.
+	Progress func(p RenderProgress)
+}
+
+// Render pulls all audio from s block by block and passes it to the sink. It returns the number
+// of rendered samples.
+//
+// When ctx is cancelled, Render stops pulling from s and the sink sees the Streamer drain, so it
+// can finish cleanly (e.g. wav.Encode writes a valid file with the audio rendered so far). Render
+// then returns ctx.Err().
+//
+// Otherwise Render returns the error of the sink or, if there is none, the error of s.
+func Render(ctx context.Context, s Streamer, sink Sink, opts RenderOptions) (n int, err error) {
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = 512
+	}
+
+	r := &renderer{
+		ctx:      ctx,
+		s:        s,
+		buf:      make([][2]float64, opts.BlockSize),
+		progress: opts.Progress,
+		p:        RenderProgress{Total: -1},
+	}
+	switch ss, ok := s.(StreamSeeker); {
+	case opts.Length > 0:
+		r.p.Total = opts.Length
+	case ok:
+		r.p.Total = ss.Len() - ss.Position()
+		r.p.Estimated = true
+	}
+
+	err = sink(r)
+	r.report()
+
+	switch {
+	case r.cancelled:
+		return r.p.Done, ctx.Err()
+	case err != nil:
+		return r.p.Done, err
+	default:
+		return r.p.Done, s.Err()
+	}
+}
+
+type renderer struct {
+	ctx       context.Context
+	s         Streamer
+	buf       [][2]float64 // the last block pulled from s
+	pending   [][2]float64 // part of buf not yet passed to the sink
+	progress  func(p RenderProgress)
+	p         RenderProgress
+	drained   bool
+	cancelled bool
+}
+
+func (r *renderer) Stream(samples [][2]float64) (n int, ok bool) {
+	for len(samples) > 0 {
+		if len(r.pending) == 0 {
+			if r.drained {
+				break
+			}
+			if r.ctx.Err() != nil {
+				r.drained, r.cancelled = true, true
+				break
+			}
+			sn, sok := r.s.Stream(r.buf)
+			if !sok {
+				r.drained = true
+			}
+			r.pending = r.buf[:sn]
+			r.p.Done += sn
+			r.report()
+		}
+		cn := copy(samples, r.pending)
+		r.pending = r.pending[cn:]
+		samples = samples[cn:]
+		n += cn
+	}
+	if n == 0 && r.drained {
+		return 0, false
+	}
+	return n, true
+}
+
+func (r *renderer) Err() error {
+	return r.s.Err()
+}
+
+func (r *renderer) report() {
+	if r.p.Total >= 0 && r.p.Done > r.p.Total {
+		r.p.Total = r.p.Done
+	}
+	if r.progress != nil {
+		r.progress(r.p)
+	}
+}
@@ -0,0 +1,88 @@
+package beep_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+)
+
+func TestRender(t *testing.T) {
+	s, data := randomDataStreamer(10000)
+
+	var (
+		got      [][2]float64
+		progress []beep.RenderProgress
+	)
+	n, err := beep.Render(context.Background(), s, func(s beep.Streamer) error {
+		got = beeptest.Collect(s)
+		return nil
+	}, beep.RenderOptions{
+		BlockSize: 1000,
+		Progress:  func(p beep.RenderProgress) { progress = append(progress, p) },
+	})
+
+	if err != nil || n != len(data) {
+		t.Fatalf("Render returned %v, %v", n, err)
+	}
+	if !reflect.DeepEqual(data, got) {
+		t.Error("Render did not pass all of the data to the sink")
+	}
+	last := progress[len(progress)-1]
+	if last.Done != len(data) || last.Total != len(data) || !last.Estimated || last.Fraction() != 1 {
+		t.Errorf("unexpected final progress: %+v", last)
+	}
+	if len(progress) < 10 {
+		t.Errorf("expected progress for every block, got %d reports", len(progress))
+	}
+}
+
+func TestRenderCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var got [][2]float64
+	n, err := beep.Render(ctx, beep.Silence(-1), func(s beep.Streamer) error {
+		got = beeptest.Collect(s)
+		return nil
+	}, beep.RenderOptions{
+		BlockSize: 100,
+		Progress: func(p beep.RenderProgress) {
+			if p.Total != -1 {
+				t.Errorf("expected unknown total, got %d", p.Total)
+			}
+			if p.Done >= 500 {
+				cancel()
+			}
+		},
+	})
+
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if n != 500 || len(got) != 500 {
+		t.Errorf("expected to render 500 samples, rendered %d, sink got %d", n, len(got))
+	}
+}
+
+func TestRenderSinkError(t *testing.T) {
+	s, _ := randomDataStreamer(1000)
+	sinkErr := errors.New("disk full")
+	_, err := beep.Render(context.Background(), s, func(s beep.Streamer) error {
+		return sinkErr
+	}, beep.RenderOptions{Length: 1000})
+	if err != sinkErr {
+		t.Errorf("expected the sink error, got %v", err)
+	}
+}
+
+func TestRenderValid(t *testing.T) {
+	s, _ := randomDataStreamer(5000)
+	beep.Render(context.Background(), s, func(s beep.Streamer) error {
+		beeptest.Check(t, s, -1)
+		return nil
+	}, beep.RenderOptions{BlockSize: 333})
+}
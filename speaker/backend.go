@@ -0,0 +1,209 @@
+package speaker
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// Config describes the audio data a Speaker writes to its Backend.
+type Config struct {
+	// Format is the format of the samples. The samples are interleaved, little-endian and
+	// signed, except for the precision of 1 byte, which is unsigned. The number of channels is 1
+	// or 2 and the precision is 1, 2 or 3 bytes.
+	Format beep.Format
+
+	// Float means that the samples are 32-bit IEEE floats instead of integers. Format.Precision
+	// is 4 in that case.
+	Float bool
+
+	// BufferSize is the number of samples the Speaker writes at once.
+	BufferSize int
+}
+
+// Backend is an audio output of a Speaker.
+//
+// The Speaker calls Write from its playback goroutine in a loop, so Write should block until the
+// output is ready for more data to pace the playback.
+type Backend interface {
+	// Open prepares the Backend for the data described by cfg.
+	Open(cfg Config) error
+
+	// Write plays the encoded samples.
+	Write(p []byte) (n int, err error)
+
+	// Close releases the resources of the Backend. The Backend may be opened again after Close.
+	Close() error
+}
+
+// LatencyBackend is a Backend which knows how many of the samples written to it were not played
+// yet. It allows the Speaker to report the playback position accurately.
+//
+// A Speaker assumes that a Backend which doesn't implement LatencyBackend buffers BufferSize
+// samples, as the Oto Backend does.
+type LatencyBackend interface {
+	Backend
+
+	// Latency returns the number of written samples which were not played yet. It may be
+	// called from any goroutine.
+	Latency() int
+}
+
+func backendLatency(b Backend, cfg Config) func() int {
+	if lb, ok := b.(LatencyBackend); ok {
+		return lb.Latency
+	}
+	return func() int { return cfg.BufferSize }
+}
+
+// EnvBackend is the name of the environment variable which selects the Backend used when none is
+// set in the Options. The recognized values are:
+//
+//   oto          plays through the audio device (default)
+//   null         discards the audio at real-time pace
+//   wav:<path>   writes the audio to the WAV file at path at real-time pace
+//   pipe         writes raw PCM to the standard output at real-time pace
+//
+// The WAV file is finalized when the Speaker is closed, see WAVFile.
+const EnvBackend = "BEEP_SPEAKER_BACKEND"
+
+// backendFromEnv returns the Backend selected by the EnvBackend environment variable.
+func backendFromEnv() (Backend, error) {
+	name := os.Getenv(EnvBackend)
+	switch {
+	case name == "" || name == "oto":
+		return Oto(), nil
+	case name == "null":
+		return Null(), nil
+	case strings.HasPrefix(name, "wav:"):
+		return WAVFile(strings.TrimPrefix(name, "wav:")), nil
+	case name == "pipe":
+		// the standard output may be redirected to a file, which doesn't block
+		return &pacedPipeBackend{pipeBackend: pipeBackend{w: os.Stdout}}, nil
+	default:
+		return nil, fmt.Errorf("speaker: unknown backend in %s: %q", EnvBackend, name)
+	}
+}
+
+// pacer blocks writes so that they don't run ahead of real time by more than one buffer.
+type pacer struct {
+	// accessed atomically, kept first for alignment
+	written int64 // number of written samples
+	start   int64 // time of the first sample in Unix nanoseconds
+
+	cfg Config
+}
+
+func (p *pacer) reset(cfg Config) {
+	p.cfg = cfg
+	atomic.StoreInt64(&p.start, time.Now().UnixNano())
+	atomic.StoreInt64(&p.written, 0)
+}
+
+// wait blocks until numBytes more bytes can be written.
+func (p *pacer) wait(numBytes int) {
+	n := int64(numBytes / p.cfg.Format.Width())
+	defer atomic.AddInt64(&p.written, n)
+
+	written := atomic.LoadInt64(&p.written)
+	start := time.Unix(0, atomic.LoadInt64(&p.start))
+	if elapsed := p.cfg.Format.SampleRate.N(time.Since(start)); int64(elapsed) > written {
+		// the writes fell behind, e.g. because the playback was suspended, so the clock
+		// restarts like a device after an underrun instead of rushing to catch up
+		start = time.Now().Add(-p.cfg.Format.SampleRate.D(int(written)))
+		atomic.StoreInt64(&p.start, start.UnixNano())
+	}
+
+	ahead := int(written+n) - p.cfg.BufferSize
+	if ahead <= 0 {
+		return
+	}
+	if d := time.Until(start.Add(p.cfg.Format.SampleRate.D(ahead))); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// latency returns the number of written samples ahead of real time.
+func (p *pacer) latency() int {
+	start := time.Unix(0, atomic.LoadInt64(&p.start))
+	elapsed := p.cfg.Format.SampleRate.N(time.Since(start))
+	if ahead := int(atomic.LoadInt64(&p.written)) - elapsed; ahead > 0 {
+		return ahead
+	}
+	return 0
+}
+
+// Null returns a Backend which discards all of the audio. It consumes the audio at real-time pace,
+// so the playback behaves just like with an audio device. It's useful on machines without one.
+func Null() Backend {
+	return &nullBackend{}
+}
+
+type nullBackend struct {
+	pacer pacer
+}
+
+func (nb *nullBackend) Open(cfg Config) error {
+	nb.pacer.reset(cfg)
+	return nil
+}
+
+func (nb *nullBackend) Write(p []byte) (n int, err error) {
+	nb.pacer.wait(len(p))
+	return len(p), nil
+}
+
+func (nb *nullBackend) Latency() int {
+	return nb.pacer.latency()
+}
+
+func (nb *nullBackend) Close() error {
+	return nil
+}
+
+// Pipe returns a Backend which writes raw PCM data to w, e.g. to the standard output to pipe it
+// into another program. The writes are not paced, so w should block to set the pace.
+func Pipe(w io.Writer) Backend {
+	return &pipeBackend{w: w}
+}
+
+type pipeBackend struct {
+	w io.Writer
+}
+
+func (pb *pipeBackend) Open(cfg Config) error {
+	return nil
+}
+
+func (pb *pipeBackend) Write(p []byte) (n int, err error) {
+	return pb.w.Write(p)
+}
+
+func (pb *pipeBackend) Close() error {
+	return nil
+}
+
+// pacedPipeBackend is a Pipe which writes at real-time pace, for writers which don't block.
+type pacedPipeBackend struct {
+	pipeBackend
+	pacer pacer
+}
+
+func (pb *pacedPipeBackend) Open(cfg Config) error {
+	pb.pacer.reset(cfg)
+	return nil
+}
+
+func (pb *pacedPipeBackend) Write(p []byte) (n int, err error) {
+	pb.pacer.wait(len(p))
+	return pb.w.Write(p)
+}
+
+func (pb *pacedPipeBackend) Latency() int {
+	return pb.pacer.latency()
+}
@@ -0,0 +1,252 @@
+package speaker_test
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/wav"
+	"github.com/pkg/errors"
+)
+
+func TestWAVFileBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "speaker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.wav")
+
+	sr := beep.SampleRate(8000)
+	s, err := speaker.New(speaker.Options{
+		SampleRate: sr,
+		BufferSize: 80,
+		Backend:    speaker.WAVFile(path),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := s.PlayAndWait(ctx, beeptest.Constant([2]float64{0.5, -0.5}, 400)); err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, format, err := wav.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	if format.SampleRate != sr || format.NumChannels != 2 || format.Precision != 2 {
+		t.Errorf("unexpected format: %+v", format)
+	}
+	// the file is written at real-time pace
+	if d.Len() > sr.N(elapsed+100*time.Millisecond) {
+		t.Errorf("unexpected length of %v for %v of playback", sr.D(d.Len()), elapsed)
+	}
+
+	// the speaker may write silence before the Streamer starts playing
+	got := beeptest.Collect(d)
+	for len(got) > 0 && got[0] == [2]float64{} {
+		got = got[1:]
+	}
+	if len(got) < 400 {
+		t.Fatalf("expected 400 samples in the file, got %d", len(got))
+	}
+	for i := range got[:400] {
+		if math.Abs(got[i][0]-0.5) > 1e-4 || math.Abs(got[i][1]+0.5) > 1e-4 {
+			t.Fatalf("unexpected sample %d in the file: %v", i, got[i])
+		}
+	}
+	if len(got) > 400 && got[400] != [2]float64{} {
+		t.Errorf("expected silence after the Streamer, got %v", got[400])
+	}
+}
+
+func TestBackendFromEnv(t *testing.T) {
+	defer os.Unsetenv(speaker.EnvBackend)
+
+	os.Setenv(speaker.EnvBackend, "bogus")
+	if _, err := speaker.New(speaker.Options{SampleRate: 8000, BufferSize: 80}); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+
+	os.Setenv(speaker.EnvBackend, "null")
+	s, err := speaker.New(speaker.Options{SampleRate: 8000, BufferSize: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+}
+
+type closeErrBackend struct {
+	speaker.Backend
+	err error
+}
+
+func (b closeErrBackend) Close() error {
+	b.Backend.Close()
+	return b.err
+}
+
+func TestCloseError(t *testing.T) {
+	errClose := errors.New("disk full")
+	s, err := speaker.New(speaker.Options{
+		SampleRate: 8000,
+		BufferSize: 80,
+		Backend:    closeErrBackend{speaker.Null(), errClose},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != errClose {
+		t.Errorf("expected the error of the Backend, got %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("expected no error closing a closed Speaker, got %v", err)
+	}
+}
+
+func TestNullBackendLatency(t *testing.T) {
+	s, err := speaker.New(speaker.Options{SampleRate: 8000, BufferSize: 800, Backend: speaker.Null()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ss := beeptest.Data(make([][2]float64, 80000))
+	s.Play(ss)
+	time.Sleep(300 * time.Millisecond)
+
+	// the speaker pulls one buffer ahead, the null backend buffers another one
+	if l := s.Latency(); l < 50*time.Millisecond || l > 250*time.Millisecond {
+		t.Errorf("unexpected latency: %v", l)
+	}
+	played := time.Duration(s.Played()) * time.Second / 8000
+	if played < 150*time.Millisecond || played > 350*time.Millisecond {
+		t.Errorf("unexpected played duration after 300ms: %v", played)
+	}
+	s.Lock()
+	audible, position := s.AudiblePosition(ss, 8000), ss.Position()
+	s.Unlock()
+	if audible >= position {
+		t.Errorf("audible position %d not behind the streamer position %d", audible, position)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	s, err := speaker.New(speaker.Options{
+		SampleRate:  8000,
+		BufferSize:  80,
+		Backend:     speaker.Null(),
+		IdleTimeout: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for !s.Idle() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !s.Idle() {
+		t.Fatal("expected the speaker to become idle")
+	}
+	time.Sleep(30 * time.Millisecond) // let the buffered samples play
+	played := s.Played()
+	time.Sleep(50 * time.Millisecond)
+	if s.Played() != played {
+		t.Errorf("expected the speaker to stop writing while idle, played %d, then %d", played, s.Played())
+	}
+
+	s.Play(beeptest.Constant([2]float64{0.5, 0.5}, 800))
+	time.Sleep(50 * time.Millisecond)
+	if s.Played() <= played {
+		t.Error("expected the speaker to play after waking up")
+	}
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write(p []byte) (n int, err error) { return 0, w.err }
+
+func TestWriteError(t *testing.T) {
+	errWrite := errors.New("disk full")
+	s, err := speaker.New(speaker.Options{
+		SampleRate: 8000,
+		BufferSize: 80,
+		Backend:    speaker.Pipe(errWriter{errWrite}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.PlayAndWait(ctx, beeptest.Constant([2]float64{}, 800)); errors.Cause(err) != errWrite {
+		t.Errorf("expected PlayAndWait to fail with the write error, got %v", err)
+	}
+	if err := s.Close(); errors.Cause(err) != errWrite {
+		t.Errorf("expected Close to report the write error, got %v", err)
+	}
+}
+
+func TestPlayAndWaitIdle(t *testing.T) {
+	// a Pipe doesn't report its latency and the IdleTimeout is shorter than a block, so the
+	// Speaker must not go idle before the end of the Streamer is pushed out of the buffer
+	s, err := speaker.New(speaker.Options{
+		SampleRate:  8000,
+		BufferSize:  800,
+		IdleTimeout: 10 * time.Millisecond,
+		Backend:     speaker.Pipe(ioutil.Discard),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.PlayAndWait(ctx, beep.Take(400, beep.Silence(-1))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	s, err := speaker.New(speaker.Options{SampleRate: 8000, BufferSize: 80, Backend: speaker.Null()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// only check that the playback goroutine records the blocks, the counting is tested
+	// internally, since the timing of real playback isn't deterministic
+	deadline := time.Now().Add(time.Second)
+	for s.Stats().Blocks < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("blocks not recorded: %+v", s.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if st := s.Stats(); st.Duration != time.Duration(st.Blocks)*10*time.Millisecond {
+		t.Errorf("unexpected duration of the mixed blocks: %+v", st)
+	}
+}
@@ -0,0 +1,88 @@
+package speaker
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// Played returns the number of samples played through the default Speaker since Init.
+func Played() int {
+	return defaultSpeaker.Played()
+}
+
+// Latency returns the current latency of the default Speaker.
+func Latency() time.Duration {
+	return defaultSpeaker.Latency()
+}
+
+// AudiblePosition returns the position of ss which is audible right now through the default
+// Speaker.
+func AudiblePosition(ss beep.StreamSeeker, sr beep.SampleRate) int {
+	return defaultSpeaker.AudiblePosition(ss, sr)
+}
+
+// SampleRate returns the sample rate the Speaker was initialized with.
+func (s *Speaker) SampleRate() beep.SampleRate {
+	return beep.SampleRate(atomic.LoadInt64(&s.rate))
+}
+
+// Played returns the number of samples which were actually played, that is, written to the
+// Backend and not buffered by it anymore. The value never decreases until the Speaker is
+// initialized again. It may be called from any goroutine, even while the Speaker is locked.
+func (s *Speaker) Played() int {
+	if s.latency.Load() == nil {
+		return 0
+	}
+	played := atomic.LoadInt64(&s.written) - int64(s.latency.Load().(func() int)())
+	for {
+		max := atomic.LoadInt64(&s.played)
+		if played <= max {
+			return int(max)
+		}
+		if atomic.CompareAndSwapInt64(&s.played, max, played) {
+			return int(played)
+		}
+	}
+}
+
+// latencySamples returns the number of samples pulled from the playing Streamers, which were not
+// played yet.
+func (s *Speaker) latencySamples() int {
+	if s.latency.Load() == nil {
+		return 0
+	}
+	latency := int(atomic.LoadInt64(&s.pulled)+atomic.LoadInt64(&s.delay)) - s.Played()
+	if latency < 0 {
+		return 0
+	}
+	return latency
+}
+
+// Latency returns the time between pulling a sample from the playing Streamers and hearing it. It
+// includes the samples pulled but not yet written to the Backend, the delay of the limiter and the
+// samples buffered by the Backend. It may be called from any goroutine, even while the Speaker is locked.
+func (s *Speaker) Latency() time.Duration {
+	return s.SampleRate().D(s.latencySamples())
+}
+
+// AudiblePosition maps the position of a playing StreamSeeker to the position which is audible
+// right now. Since the Speaker pulls samples ahead, ss.Position() runs ahead of what's heard by
+// the latency of the Speaker.
+//
+// The sr argument is the sample rate of ss. If it differs from the Speaker's sample rate (e.g.
+// ss is played through beep.Resample), the latency is converted accordingly.
+//
+// Lock the Speaker around the call, like around any call to a playing Streamer.
+func (s *Speaker) AudiblePosition(ss beep.StreamSeeker, sr beep.SampleRate) int {
+	latency := s.latencySamples()
+	if speakerRate := s.SampleRate(); speakerRate != 0 && sr != speakerRate {
+		latency = int(int64(latency) * int64(sr) / int64(speakerRate))
+	}
+	p := ss.Position() - latency
+	if p < 0 {
+		return 0
+	}
+	return p
+}
@@ -0,0 +1,79 @@
+package speaker
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// encoder converts samples to the byte format described by a Config.
+type encoder struct {
+	cfg    Config
+	dither bool
+	rng    uint64 // xorshift state for the dither noise
+}
+
+func newEncoder(cfg Config, dither bool) *encoder {
+	return &encoder{cfg: cfg, dither: dither && !cfg.Float, rng: 0x9e3779b97f4a7c15}
+}
+
+// encode encodes the samples to p, which must have the room for all of them. The samples must be
+// clipped to [-1, +1].
+func (e *encoder) encode(p []byte, samples [][2]float64) {
+	width := e.cfg.Format.Precision
+	for _, sample := range samples {
+		switch e.cfg.Format.NumChannels {
+		case 1:
+			e.encodeValue(p, (sample[0]+sample[1])/2)
+			p = p[width:]
+		case 2:
+			e.encodeValue(p, sample[0])
+			e.encodeValue(p[width:], sample[1])
+			p = p[2*width:]
+		default:
+			panic(fmt.Errorf("speaker: encode: invalid number of channels: %d", e.cfg.Format.NumChannels))
+		}
+	}
+}
+
+func (e *encoder) encodeValue(p []byte, x float64) {
+	if e.cfg.Float {
+		binary.LittleEndian.PutUint32(p, math.Float32bits(float32(x)))
+		return
+	}
+
+	precision := e.cfg.Format.Precision
+	max := math.Exp2(float64(precision*8-1)) - 1
+	x *= max
+	if e.dither {
+		// triangular noise with the amplitude of one least significant bit
+		x += e.random() - e.random()
+	}
+	x = math.Round(x)
+	if x < -max {
+		x = -max
+	}
+	if x > max {
+		x = max
+	}
+	v := int64(x)
+
+	switch precision {
+	case 1:
+		p[0] = byte(v + 128)
+	case 2:
+		binary.LittleEndian.PutUint16(p, uint16(v))
+	case 3:
+		p[0], p[1], p[2] = byte(v), byte(v>>8), byte(v>>16)
+	default:
+		panic(fmt.Errorf("speaker: encode: invalid precision: %d", precision))
+	}
+}
+
+// random returns a uniformly distributed number in [0, 1).
+func (e *encoder) random() float64 {
+	e.rng ^= e.rng << 13
+	e.rng ^= e.rng >> 7
+	e.rng ^= e.rng << 17
+	return float64(e.rng>>11) / (1 << 53)
+}
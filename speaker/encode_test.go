@@ -0,0 +1,74 @@
+package speaker
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		cfg    Config
+		sample [2]float64
+		want   []byte
+	}{
+		{
+			cfg:    Config{Format: beep.Format{NumChannels: 2, Precision: 2}},
+			sample: [2]float64{0.5, -1},
+			want:   []byte{0x00, 0x40, 0x01, 0x80},
+		},
+		{
+			cfg:    Config{Format: beep.Format{NumChannels: 1, Precision: 3}},
+			sample: [2]float64{1, 0},
+			want:   []byte{0x00, 0x00, 0x40},
+		},
+		{
+			cfg:    Config{Format: beep.Format{NumChannels: 1, Precision: 1}},
+			sample: [2]float64{0, 0},
+			want:   []byte{0x80},
+		},
+		{
+			cfg:    Config{Format: beep.Format{NumChannels: 1, Precision: 4}, Float: true},
+			sample: [2]float64{0.25, 0.25},
+			want:   []byte{0x00, 0x00, 0x80, 0x3e},
+		},
+	}
+
+	for _, tt := range tests {
+		got := make([]byte, len(tt.want))
+		newEncoder(tt.cfg, false).encode(got, [][2]float64{tt.sample})
+		if string(got) != string(tt.want) {
+			t.Errorf("encoding %v with %+v: got %x, want %x", tt.sample, tt.cfg, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeDither(t *testing.T) {
+	cfg := Config{Format: beep.Format{NumChannels: 1, Precision: 2}}
+	lsb := 1 / (math.Exp2(15) - 1)
+
+	// a constant signal of a quarter of the least significant bit
+	samples := make([][2]float64, 100000)
+	for i := range samples {
+		samples[i] = [2]float64{lsb / 4, lsb / 4}
+	}
+
+	mean := func(dither bool) float64 {
+		p := make([]byte, len(samples)*2)
+		newEncoder(cfg, dither).encode(p, samples)
+		sum := 0.0
+		for i := 0; i < len(p); i += 2 {
+			sum += float64(int16(binary.LittleEndian.Uint16(p[i:])))
+		}
+		return sum / float64(len(samples))
+	}
+
+	if m := mean(false); m != 0 {
+		t.Errorf("expected the signal to be rounded away without dither, got mean %v", m)
+	}
+	if m := mean(true); math.Abs(m-0.25) > 0.02 {
+		t.Errorf("expected dither to preserve the mean of 0.25 LSB, got %v", m)
+	}
+}
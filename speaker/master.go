@@ -0,0 +1,136 @@
+package speaker
+
+import (
+	"sync/atomic"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/effects"
+)
+
+// LimiterOptions configure the look-ahead limiter on the master output of a Speaker. It's the same
+// limiter as effects.Limiter.
+type LimiterOptions = effects.LimiterOptions
+
+// SetVolume sets the master gain of the default Speaker.
+func SetVolume(volume float64) {
+	defaultSpeaker.SetVolume(volume)
+}
+
+// SetVolumeDB sets the master gain of the default Speaker in decibels.
+func SetVolumeDB(db float64) {
+	defaultSpeaker.SetVolumeDB(db)
+}
+
+// SetMuted mutes or unmutes the default Speaker.
+func SetMuted(muted bool) {
+	defaultSpeaker.SetMuted(muted)
+}
+
+// SetLimiter enables, reconfigures or, if opts is nil, disables the limiter of the default
+// Speaker.
+func SetLimiter(opts *LimiterOptions) {
+	defaultSpeaker.SetLimiter(opts)
+}
+
+// GainReduction returns the maximal gain reduction of the default Speaker's limiter since the
+// last call.
+func GainReduction() float64 {
+	return defaultSpeaker.GainReduction()
+}
+
+// SetVolume sets the master gain applied to the mixed output before the limiter. 1 leaves the
+// output unchanged, 0.5 halves the amplitude, and so on. Negative values invert the phase.
+func (s *Speaker) SetVolume(volume float64) {
+	s.mu.Lock()
+	s.volume = volume
+	s.mu.Unlock()
+}
+
+// Volume returns the master gain of the Speaker.
+func (s *Speaker) Volume() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.volume
+}
+
+// SetVolumeDB sets the master gain in decibels, with the same semantics as effects.Fader. 0 dB
+// leaves the output unchanged and negative infinity silences it.
+func (s *Speaker) SetVolumeDB(db float64) {
+	s.SetVolume(beep.DBToGain(db))
+}
+
+// VolumeDB returns the master gain of the Speaker in decibels.
+func (s *Speaker) VolumeDB() float64 {
+	return beep.GainToDB(s.Volume())
+}
+
+// SetMuted mutes or unmutes the Speaker. The playing Streamers keep playing while the Speaker is
+// muted, they're only not heard.
+func (s *Speaker) SetMuted(muted bool) {
+	s.mu.Lock()
+	s.muted = muted
+	s.mu.Unlock()
+}
+
+// Muted returns whether the Speaker is muted.
+func (s *Speaker) Muted() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.muted
+}
+
+// SetLimiter enables, reconfigures or, if opts is nil, disables the limiter on the master output.
+//
+// Changing the Lookahead, as well as enabling or disabling the limiter, changes the delay of the
+// output and causes a short glitch. Threshold and Release can be changed seamlessly.
+func (s *Speaker) SetLimiter(opts *LimiterOptions) {
+	s.mu.Lock()
+	s.setLimiter(opts)
+	s.mu.Unlock()
+}
+
+// setLimiter is SetLimiter without locking.
+func (s *Speaker) setLimiter(opts *LimiterOptions) {
+	sr := s.SampleRate()
+	switch {
+	case opts == nil:
+		s.limiter = nil
+	case s.limiter != nil:
+		s.limiter.SetOptions(*opts)
+	default:
+		s.limiter = effects.NewLimiter(&s.master, sr, *opts)
+	}
+	var delay int
+	if s.limiter != nil {
+		delay = s.limiter.Latency()
+	}
+	atomic.StoreInt64(&s.delay, int64(delay))
+}
+
+// GainReduction returns the maximal gain reduction applied by the limiter since the last call, in
+// decibels. It's 0 if the limiter didn't reduce the gain or is disabled. Calling it periodically,
+// e.g. once per frame, gives the reading for a gain reduction meter.
+func (s *Speaker) GainReduction() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.limiter == nil {
+		return 0
+	}
+	return s.limiter.GainReduction()
+}
+
+// block is a Streamer which streams the samples it's given in place. The limiter on the master
+// output streams the mixed samples through it.
+type block struct {
+	samples [][2]float64
+}
+
+func (b *block) Stream(samples [][2]float64) (n int, ok bool) {
+	n = copy(samples, b.samples)
+	b.samples = b.samples[n:]
+	return n, true
+}
+
+func (b *block) Err() error {
+	return nil
+}
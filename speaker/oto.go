@@ -0,0 +1,70 @@
+package speaker
+
+import (
+	"sync"
+
+	"github.com/hajimehoshi/oto"
+	"github.com/pkg/errors"
+)
+
+var (
+	// oto supports only a single context at a time
+	otoMu    sync.Mutex
+	otoInUse bool
+)
+
+// Oto returns a Backend which plays through the audio device using the Oto library. This is the
+// default Backend.
+//
+// Only one Oto Backend can be open at a time, since Oto supports only a single output per process.
+func Oto() Backend {
+	return &otoBackend{}
+}
+
+type otoBackend struct {
+	context *oto.Context
+	player  *oto.Player
+}
+
+func (ob *otoBackend) Open(cfg Config) error {
+	otoMu.Lock()
+	defer otoMu.Unlock()
+	if otoInUse {
+		return errors.New("oto: another speaker is already playing")
+	}
+	if cfg.Float || cfg.Format.Precision > 2 {
+		return errors.New("oto: unsupported format, only 8 and 16-bit integer samples are supported")
+	}
+	var err error
+	ob.context, err = oto.NewContext(
+		int(cfg.Format.SampleRate),
+		cfg.Format.NumChannels,
+		cfg.Format.Precision,
+		cfg.BufferSize*cfg.Format.Width(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "oto")
+	}
+	otoInUse = true
+	ob.player = ob.context.NewPlayer()
+	return nil
+}
+
+func (ob *otoBackend) Write(p []byte) (n int, err error) {
+	return ob.player.Write(p)
+}
+
+func (ob *otoBackend) Close() error {
+	if ob.context == nil {
+		return nil
+	}
+	ob.player.Close()
+	err := ob.context.Close()
+	ob.player, ob.context = nil, nil
+
+	otoMu.Lock()
+	otoInUse = false
+	otoMu.Unlock()
+
+	return err
+}
@@ -0,0 +1,40 @@
+package speaker
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+func TestPipeFromEnvIsPaced(t *testing.T) {
+	defer os.Unsetenv(EnvBackend)
+	os.Setenv(EnvBackend, "pipe")
+	b, err := backendFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.(*pacedPipeBackend); !ok {
+		t.Fatalf("expected a paced pipe, got %T", b)
+	}
+
+	pb := &pacedPipeBackend{pipeBackend: pipeBackend{w: ioutil.Discard}}
+	cfg := Config{
+		Format:     beep.Format{SampleRate: 8000, NumChannels: 2, Precision: 2},
+		BufferSize: 80,
+	}
+	if err := pb.Open(cfg); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	buf := make([]byte, cfg.BufferSize*cfg.Format.Width())
+	for i := 0; i < 10; i++ {
+		pb.Write(buf)
+	}
+	// all but the first buffer are written at real-time pace
+	if elapsed := time.Since(start); elapsed < 85*time.Millisecond {
+		t.Errorf("expected 800 samples to take 90ms to write, took %v", elapsed)
+	}
+}
@@ -0,0 +1,58 @@
+package speaker
+
+// Suspend suspends the default Speaker.
+func Suspend() {
+	defaultSpeaker.Suspend()
+}
+
+// Resume resumes the default Speaker after Suspend.
+func Resume() {
+	defaultSpeaker.Resume()
+}
+
+// Suspend stops the Speaker from pulling samples from the playing Streamers and writing them to
+// the Backend, until Resume is called. The playing Streamers stay in the Speaker, paused.
+func (s *Speaker) Suspend() {
+	s.mu.Lock()
+	s.paused = true
+	s.mu.Unlock()
+}
+
+// Resume resumes the playback after Suspend.
+func (s *Speaker) Resume() {
+	s.mu.Lock()
+	s.paused = false
+	s.wakeUp()
+	s.mu.Unlock()
+}
+
+// Suspended returns whether the Speaker is suspended by Suspend.
+func (s *Speaker) Suspended() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// Idle returns whether the Speaker is in the idle mode, that is, nothing played for the
+// IdleTimeout set in the Options. The Speaker doesn't pull or write any samples while idle. The
+// next call to Play wakes it up.
+func (s *Speaker) Idle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.idle
+}
+
+// asleep returns whether the Speaker is suspended or idle.
+func (s *Speaker) asleep() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused || s.idle
+}
+
+// wakeUp wakes the playback goroutine up, if it's waiting. The Speaker must be locked.
+func (s *Speaker) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
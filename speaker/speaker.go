@@ -2,22 +2,126 @@
 package speaker
 
 import (
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/faiface/beep"
-	"github.com/hajimehoshi/oto"
+	"github.com/faiface/beep/effects"
 	"github.com/pkg/errors"
 )
 
-var (
+// Options configure a Speaker.
+type Options struct {
+	// SampleRate is the sample rate of the playback.
+	SampleRate beep.SampleRate
+
+	// BufferSize is the number of samples of the speaker's buffer. Bigger BufferSize means
+	// lower CPU usage and more reliable playback. Lower BufferSize means better responsiveness
+	// and less delay.
+	BufferSize int
+
+	// NumChannels is the number of output channels, 1 (mono) or 2 (stereo). Defaults to 2.
+	NumChannels int
+
+	// Precision is the number of bytes per output sample in one channel, 1, 2 or 3 (8, 16 or
+	// 24 bits). Defaults to 2. Not all Backends support all precisions, Oto supports 1 and 2.
+	Precision int
+
+	// Float selects 32-bit floating point output instead of integer, Precision is ignored.
+	Float bool
+
+	// Dither adds triangular (TPDF) dither noise before quantizing the output to integers. This
+	// turns the quantization distortion of quiet sounds into a constant low noise floor.
+	Dither bool
+
+	// Volume is the master gain applied to the mixed output. Zero means 1, use Muted to silence
+	// the output.
+	Volume float64
+
+	// Muted silences the output, while the Streamers keep playing.
+	Muted bool
+
+	// Limiter enables a look-ahead limiter on the master output, which reduces the gain smoothly
+	// instead of hard clipping loud passages. If nil, the output is only clipped.
+	Limiter *LimiterOptions
+
+	// IdleTimeout enables the idle mode. When nothing plays for IdleTimeout, the Speaker stops
+	// pulling and writing samples until the next call to Play. Zero disables the idle mode. The
+	// Speaker writes at least two blocks of silence before going idle, so that the end of the
+	// audio is played out of the Backend's buffer.
+	IdleTimeout time.Duration
+
+	// OnLate is called from the playback goroutine after a block took longer to mix than to play
+	// or the Backend ran out of samples. It must return quickly. See Stats.
+	OnLate func(Stats)
+
+	// Backend is the audio output. If nil, the Backend is selected by the EnvBackend environment
+	// variable, which defaults to Oto.
+	Backend Backend
+}
+
+// Speaker plays Streamers through an audio output Backend. Each Speaker has its own mixer, lock
+// and playback goroutine.
+//
+// The package-level functions operate on a default Speaker, which is initialized by Init.
+type Speaker struct {
+	// accessed atomically, kept first for alignment
+	pulled  int64 // number of samples pulled from the mixer
+	written int64 // number of samples written to the backend
+	played  int64 // the highest value returned by Played
+	rate    int64 // sample rate
+	delay   int64 // number of samples the limiter delays the output by
+
+	latency atomic.Value // func() int returning the number of samples buffered by the backend
+
 	mu      sync.Mutex
 	mixer   beep.Mixer
+	volume  float64
+	muted   bool
+	limiter *effects.Limiter
+	master  block // the source of the limiter
+	timeout int   // IdleTimeout in samples
+	silent  int   // number of samples since the mixer became empty
+	idle    bool
+	paused  bool // suspended by Suspend
+	wake    chan struct{}
 	samples [][2]float64
 	buf     []byte
-	context *oto.Context
-	player  *oto.Player
+	enc     *encoder
+	backend Backend
 	done    chan struct{}
-)
+	exited  chan struct{}
+	err     error // the first error of writing to the backend, which stopped the playback
+
+	tapMu sync.Mutex
+	taps  []*Tap
+
+	statsMu   sync.Mutex
+	stats     Stats
+	onLate    func(Stats)
+	underruns bool // the Backend reports its latency, so underruns can be detected
+}
+
+var defaultSpeaker = &Speaker{}
+
+// New creates a Speaker and starts the playback. Close it when it's no longer needed.
+//
+// Any number of Speakers can play at the same time, as long as their Backends allow it. Only one
+// Oto Backend can be open at a time.
+func New(opts Options) (*Speaker, error) {
+	s := &Speaker{}
+	if err := s.init(opts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Default returns the Speaker used by the package-level functions.
+func Default() *Speaker {
+	return defaultSpeaker
+}
 
 // Init initializes audio playback through speaker. Must be called before using this package.
 //
@@ -25,106 +129,307 @@ var (
 // bufferSize means lower CPU usage and more reliable playback. Lower bufferSize means better
 // responsiveness and less delay.
 func Init(sampleRate beep.SampleRate, bufferSize int) error {
-	mu.Lock()
-	defer mu.Unlock()
+	return InitWithOptions(Options{
+		SampleRate: sampleRate,
+		BufferSize: bufferSize,
+	})
+}
+
+// InitWithOptions is like Init, but allows configuring the default Speaker further, e.g. to
+// choose its Backend.
+func InitWithOptions(opts Options) error {
+	if err := defaultSpeaker.Close(); err != nil {
+		return err
+	}
+	return defaultSpeaker.init(opts)
+}
+
+// Close closes the playback and the driver. In most cases, there is certainly no need to call Close
+// even when the program doesn't play anymore, because in properly set systems, the default mixer
+// handles multiple concurrent processes. It's only when the default device is not a virtual but hardware
+// device, that you'll probably want to manually manage the device from your application.
+//
+// The WAV file Backend is an exception, it finalizes the file in Close.
+func Close() error {
+	return defaultSpeaker.Close()
+}
+
+// Lock locks the speaker. While locked, speaker won't pull new data from the playing Streamers. Lock
+// if you want to modify any currently playing Streamers to avoid race conditions.
+//
+// Always lock speaker for as little time as possible, to avoid playback glitches.
+func Lock() {
+	defaultSpeaker.Lock()
+}
+
+// Unlock unlocks the speaker. Call after modifying any currently playing Streamer.
+func Unlock() {
+	defaultSpeaker.Unlock()
+}
+
+// Play starts playing all provided Streamers through the speaker.
+func Play(s ...beep.Streamer) {
+	defaultSpeaker.Play(s...)
+}
+
+// Clear removes all currently playing Streamers from the speaker.
+func Clear() {
+	defaultSpeaker.Clear()
+}
 
-	Close()
+func (s *Speaker) init(opts Options) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	mixer = beep.Mixer{}
+	s.mixer = beep.Mixer{}
+	atomic.StoreInt64(&s.pulled, 0)
+	atomic.StoreInt64(&s.written, 0)
+	atomic.StoreInt64(&s.played, 0)
+	atomic.StoreInt64(&s.rate, int64(opts.SampleRate))
 
-	numBytes := bufferSize * 4
-	samples = make([][2]float64, bufferSize)
-	buf = make([]byte, numBytes)
+	if opts.Volume == 0 {
+		opts.Volume = 1
+	}
+	s.volume, s.muted = opts.Volume, opts.Muted
+	s.limiter = nil // the sample rate may have changed
+	s.setLimiter(opts.Limiter)
+	s.timeout = opts.SampleRate.N(opts.IdleTimeout)
+	s.silent, s.idle, s.paused = 0, false, false
+	s.err = nil
+
+	if opts.NumChannels == 0 {
+		opts.NumChannels = 2
+	}
+	if opts.Precision == 0 {
+		opts.Precision = 2
+	}
+	if opts.Float {
+		opts.Precision = 4
+	}
+	if opts.NumChannels < 1 || opts.NumChannels > 2 {
+		return fmt.Errorf("failed to initialize speaker: invalid number of channels: %d", opts.NumChannels)
+	}
+	if opts.Precision < 1 || opts.Precision > 4 || (opts.Precision == 4 && !opts.Float) {
+		return fmt.Errorf("failed to initialize speaker: invalid precision: %d", opts.Precision)
+	}
 
-	var err error
-	context, err = oto.NewContext(int(sampleRate), 2, 2, numBytes)
-	if err != nil {
+	backend := opts.Backend
+	if backend == nil {
+		var err error
+		backend, err = backendFromEnv()
+		if err != nil {
+			return errors.Wrap(err, "failed to initialize speaker")
+		}
+	}
+	cfg := Config{
+		Format: beep.Format{
+			SampleRate:  opts.SampleRate,
+			NumChannels: opts.NumChannels,
+			Precision:   opts.Precision,
+		},
+		Float:      opts.Float,
+		BufferSize: opts.BufferSize,
+	}
+	s.samples = make([][2]float64, opts.BufferSize)
+	s.buf = make([]byte, opts.BufferSize*cfg.Format.Width())
+	s.enc = newEncoder(cfg, opts.Dither)
+
+	if err := backend.Open(cfg); err != nil {
 		return errors.Wrap(err, "failed to initialize speaker")
 	}
-	player = context.NewPlayer()
+	s.backend = backend
+	s.latency.Store(backendLatency(backend, cfg))
+	_, s.underruns = backend.(LatencyBackend)
+	s.statsMu.Lock()
+	s.stats, s.onLate = Stats{}, opts.OnLate
+	s.statsMu.Unlock()
 
-	done = make(chan struct{})
+	if mb, ok := backend.(manualBackend); ok {
+		// the Backend drives the playback itself
+		mb.attach(s)
+		return nil
+	}
 
-	go func() {
+	s.done = make(chan struct{})
+	s.exited = make(chan struct{})
+	s.wake = make(chan struct{}, 1)
+
+	go func(done, exited, wake chan struct{}) {
+		defer close(exited)
+		resumed := true // the Backend is empty at the start, that's not an underrun
 		for {
 			select {
-			default:
-				update()
 			case <-done:
 				return
+			default:
 			}
+			if s.asleep() {
+				select {
+				case <-wake:
+				case <-done:
+					return
+				}
+				resumed = true
+				continue
+			}
+			if err := s.update(resumed); err != nil {
+				// writing again would most likely fail again, e.g. on a full disk, so the
+				// playback stops and Close reports the error
+				s.mu.Lock()
+				s.err = errors.Wrap(err, "speaker playback failed")
+				s.mu.Unlock()
+				return
+			}
+			resumed = false
 		}
-	}()
+	}(s.done, s.exited, s.wake)
 
 	return nil
 }
 
-// Close closes the playback and the driver. In most cases, there is certainly no need to call Close
-// even when the program doesn't play anymore, because in properly set systems, the default mixer
-// handles multiple concurrent processes. It's only when the default device is not a virtual but hardware
-// device, that you'll probably want to manually manage the device from your application.
-func Close() {
-	if player != nil {
-		if done != nil {
-			done <- struct{}{}
-			done = nil
-		}
-		player.Close()
-		context.Close()
-		player = nil
+// Close stops the playback and closes the driver. The Speaker can't be used for playback after
+// Close, except for the default Speaker, which can be initialized again by Init.
+//
+// If writing to the Backend failed, the playback stopped at that point and Close returns the
+// error. Otherwise, it returns the error of closing the Backend, e.g. a WAV file which couldn't
+// be finalized.
+func (s *Speaker) Close() error {
+	s.mu.Lock()
+	done, exited := s.done, s.exited
+	s.done, s.exited = nil, nil
+	opened := s.backend != nil
+	s.mu.Unlock()
+
+	if !opened {
+		return nil
+	}
+	if done != nil {
+		// the playback goroutine needs the lock to finish the current update
+		close(done)
+		<-exited
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.backend.Close()
+	if s.err != nil {
+		err = s.err
+	}
+	s.backend = nil
+
+	s.closeTaps()
+	return err
 }
 
-// Lock locks the speaker. While locked, speaker won't pull new data from the playing Streamers. Lock
-// if you want to modify any currently playing Streamers to avoid race conditions.
+// Lock locks the Speaker. While locked, the Speaker won't pull new data from the playing
+// Streamers. Lock if you want to modify any currently playing Streamers to avoid race conditions.
 //
-// Always lock speaker for as little time as possible, to avoid playback glitches.
-func Lock() {
-	mu.Lock()
+// Always lock the Speaker for as little time as possible, to avoid playback glitches.
+func (s *Speaker) Lock() {
+	s.mu.Lock()
 }
 
-// Unlock unlocks the speaker. Call after modifying any currently playing Streamer.
-func Unlock() {
-	mu.Unlock()
+// Unlock unlocks the Speaker. Call after modifying any currently playing Streamer.
+func (s *Speaker) Unlock() {
+	s.mu.Unlock()
 }
 
-// Play starts playing all provided Streamers through the speaker.
-func Play(s ...beep.Streamer) {
-	mu.Lock()
-	mixer.Add(s...)
-	mu.Unlock()
+// Play starts playing all provided Streamers through the Speaker.
+func (s *Speaker) Play(st ...beep.Streamer) {
+	s.mu.Lock()
+	s.mixer.Add(st...)
+	if s.idle {
+		s.idle, s.silent = false, 0
+		s.wakeUp()
+	}
+	s.mu.Unlock()
 }
 
-// Clear removes all currently playing Streamers from the speaker.
-func Clear() {
-	mu.Lock()
-	mixer.Clear()
-	mu.Unlock()
+// Clear removes all currently playing Streamers from the Speaker.
+func (s *Speaker) Clear() {
+	s.mu.Lock()
+	s.mixer.Clear()
+	s.mu.Unlock()
 }
 
 // update pulls new data from the playing Streamers and sends it to the speaker. Blocks until the
-// data is sent and started playing.
-func update() {
-	mu.Lock()
-	mixer.Stream(samples)
-	mu.Unlock()
+// data is sent and started playing. The resumed argument tells that the playback just started or
+// woke up, so the Backend is expected to be empty. It returns the error of writing to the Backend.
+func (s *Speaker) update(resumed bool) error {
+	start := time.Now()
+	s.mix(s.samples)
+	s.enc.encode(s.buf, s.samples)
+	mixTime := time.Since(start)
+
+	underrun := s.underruns && !resumed && s.latency.Load().(func() int)() == 0
+	if _, err := s.backend.Write(s.buf); err != nil {
+		return err
+	}
+	atomic.AddInt64(&s.written, int64(len(s.samples)))
+
+	s.record(mixTime, underrun)
+	return nil
+}
+
+// mix pulls len(samples) samples from the playing Streamers, applies the master volume and the
+// limiter and clips them to the output range.
+func (s *Speaker) mix(samples [][2]float64) {
+	s.mu.Lock()
+	pos := atomic.AddInt64(&s.pulled, int64(len(samples))) - int64(len(samples))
+	if s.paused || s.idle {
+		// only a Virtual Backend mixes while asleep, the time passes in silence
+		for i := range samples {
+			samples[i] = [2]float64{}
+		}
+		s.mu.Unlock()
+		return
+	}
+	s.mixer.Stream(samples)
+	if s.timeout > 0 {
+		if s.mixer.Len() > 0 {
+			s.silent = 0
+		} else if s.silent += len(samples); s.silent >= s.idleAfter() {
+			s.idle = true
+		}
+	}
+	for i := range samples {
+		samples[i][0] *= s.volume
+		samples[i][1] *= s.volume
+	}
+	if s.limiter != nil && len(samples) > 0 {
+		s.master.samples = samples
+		s.limiter.Stream(samples)
+	}
+	if s.muted {
+		// the limiter keeps running, so that unmuting doesn't play its stale contents
+		for i := range samples {
+			samples[i] = [2]float64{}
+		}
+	}
+	s.mu.Unlock()
 
 	for i := range samples {
 		for c := range samples[i] {
-			val := samples[i][c]
-			if val < -1 {
-				val = -1
+			if samples[i][c] < -1 {
+				samples[i][c] = -1
 			}
-			if val > +1 {
-				val = +1
+			if samples[i][c] > +1 {
+				samples[i][c] = +1
 			}
-			valInt16 := int16(val * (1<<15 - 1))
-			low := byte(valInt16)
-			high := byte(valInt16 >> 8)
-			buf[i*4+c*2+0] = low
-			buf[i*4+c*2+1] = high
 		}
 	}
+	s.sendTaps(samples, int(pos))
+}
 
-	player.Write(buf)
+// idleAfter returns the number of silent samples after which the Speaker goes idle. Besides the
+// IdleTimeout, the silence must flush the limiter's delay line and push the last samples out of
+// the Backend, which holds a block, because a Backend without a reported latency only counts
+// them as played when more samples are written. The block in which the mixer drained counts as
+// silent already, so that's two blocks.
+func (s *Speaker) idleAfter() int {
+	silent := s.timeout
+	if flush := 2 * len(s.samples); silent < flush {
+		silent = flush
+	}
+	return silent + int(atomic.LoadInt64(&s.delay))
 }
@@ -0,0 +1,78 @@
+package speaker
+
+import "time"
+
+// Stats are the performance statistics of a Speaker since it was initialized. They're collected
+// by the playback goroutine, so a Speaker with a Virtual Backend doesn't collect any.
+type Stats struct {
+	// Blocks is the number of blocks mixed and written to the Backend.
+	Blocks int
+
+	// Late is the number of blocks which took longer to mix than to play. Each late block
+	// shortens the buffered audio and when it runs out, the playback stutters.
+	Late int
+
+	// Underruns is the number of times the Backend ran out of samples before the next block was
+	// written. Only Backends implementing LatencyBackend allow detecting underruns.
+	Underruns int
+
+	// Duration is the total duration of the mixed blocks.
+	Duration time.Duration
+
+	// MixTime is the total time spent pulling the samples from the playing Streamers and
+	// preparing them for the Backend.
+	MixTime time.Duration
+
+	// LastMixTime and MaxMixTime are the time spent mixing the last block and the longest time
+	// spent mixing a block.
+	LastMixTime time.Duration
+	MaxMixTime  time.Duration
+}
+
+// Load returns the fraction of the real time spent mixing, that is, MixTime divided by Duration.
+// Values close to 1 mean that the playback barely keeps up.
+func (st Stats) Load() float64 {
+	if st.Duration == 0 {
+		return 0
+	}
+	return float64(st.MixTime) / float64(st.Duration)
+}
+
+// CurrentStats returns the performance statistics of the default Speaker.
+func CurrentStats() Stats {
+	return defaultSpeaker.Stats()
+}
+
+// Stats returns the performance statistics of the Speaker. Applications can use them to adapt,
+// e.g. increase the BufferSize or disable expensive effects when blocks are late.
+func (s *Speaker) Stats() Stats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.stats
+}
+
+// record updates the statistics with a block which took mixTime to mix.
+func (s *Speaker) record(mixTime time.Duration, underrun bool) {
+	s.statsMu.Lock()
+	duration := s.SampleRate().D(len(s.samples))
+	late := mixTime > duration
+	s.stats.Blocks++
+	s.stats.Duration += duration
+	s.stats.MixTime += mixTime
+	s.stats.LastMixTime = mixTime
+	if mixTime > s.stats.MaxMixTime {
+		s.stats.MaxMixTime = mixTime
+	}
+	if late {
+		s.stats.Late++
+	}
+	if underrun {
+		s.stats.Underruns++
+	}
+	stats, onLate := s.stats, s.onLate
+	s.statsMu.Unlock()
+
+	if onLate != nil && (late || underrun) {
+		onLate(stats)
+	}
+}
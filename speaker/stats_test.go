@@ -0,0 +1,47 @@
+package speaker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	var reported []Stats
+	s, err := New(Options{
+		SampleRate: 8000,
+		BufferSize: 80,
+		Backend:    NewVirtual(),
+		OnLate:     func(st Stats) { reported = append(reported, st) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// each block plays for 10ms
+	s.record(5*time.Millisecond, false)
+	if len(reported) != 0 {
+		t.Errorf("OnLate called for a block in time: %+v", reported)
+	}
+	s.record(15*time.Millisecond, false)
+	s.record(5*time.Millisecond, true)
+	if len(reported) != 2 || reported[0].Late != 1 || reported[1].Underruns != 1 {
+		t.Errorf("expected OnLate for the late block and the underrun, got %+v", reported)
+	}
+
+	want := Stats{
+		Blocks:      3,
+		Late:        1,
+		Underruns:   1,
+		Duration:    30 * time.Millisecond,
+		MixTime:     25 * time.Millisecond,
+		LastMixTime: 5 * time.Millisecond,
+		MaxMixTime:  15 * time.Millisecond,
+	}
+	if st := s.Stats(); st != want {
+		t.Errorf("got %+v, want %+v", st, want)
+	}
+	if load := s.Stats().Load(); load < 0.83 || load > 0.84 {
+		t.Errorf("expected the load of 25/30, got %v", load)
+	}
+}
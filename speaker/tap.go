@@ -0,0 +1,97 @@
+package speaker
+
+import "sync/atomic"
+
+// Block is a copy of a block of samples sent to the output by a Speaker.
+type Block struct {
+	// Samples are the samples exactly as sent to the output, after the master volume, the limiter
+	// and clipping.
+	Samples [][2]float64
+
+	// Position is the number of samples sent to the output before this block. Comparing it with
+	// Played tells when the block is heard.
+	Position int
+}
+
+// Tap delivers copies of the blocks sent to the output by a Speaker, e.g. to record exactly what
+// the user hears or to drive level meters.
+//
+// The playback never waits for a Tap. If the channel is full, the block is dropped and counted.
+// A gap in the positions of the received blocks means some blocks were dropped. No blocks are
+// delivered while the Speaker is suspended or idle.
+type Tap struct {
+	dropped int64 // accessed atomically
+
+	// C delivers the blocks. It's closed when the Tap or the Speaker is closed.
+	C <-chan Block
+
+	c chan Block
+	s *Speaker
+}
+
+// NewTap subscribes a new Tap to the default Speaker.
+func NewTap(size int) *Tap {
+	return defaultSpeaker.NewTap(size)
+}
+
+// NewTap subscribes a new Tap to the Speaker. The size argument is the number of blocks the Tap's
+// channel buffers. The size of each block is the BufferSize of the Speaker (or whatever a Virtual
+// Backend advances by).
+//
+// The Tap is closed when the Speaker is closed, or initialized again by Init in the case of the
+// default Speaker.
+func (s *Speaker) NewTap(size int) *Tap {
+	c := make(chan Block, size)
+	t := &Tap{C: c, c: c, s: s}
+	s.tapMu.Lock()
+	s.taps = append(s.taps, t)
+	s.tapMu.Unlock()
+	return t
+}
+
+// Dropped returns the number of blocks which were dropped, because the channel was full.
+func (t *Tap) Dropped() int {
+	return int(atomic.LoadInt64(&t.dropped))
+}
+
+// Close unsubscribes the Tap and closes its channel. Blocks already in the channel can still be
+// received.
+func (t *Tap) Close() {
+	t.s.tapMu.Lock()
+	defer t.s.tapMu.Unlock()
+	for i := range t.s.taps {
+		if t.s.taps[i] == t {
+			t.s.taps = append(t.s.taps[:i], t.s.taps[i+1:]...)
+			close(t.c)
+			return
+		}
+	}
+}
+
+// sendTaps sends copies of samples to all of the Taps without blocking.
+func (s *Speaker) sendTaps(samples [][2]float64, pos int) {
+	s.tapMu.Lock()
+	defer s.tapMu.Unlock()
+	for _, t := range s.taps {
+		// only this function sends, so the send can't block if there's room, and the copy is not
+		// made for nothing
+		if len(t.c) == cap(t.c) {
+			atomic.AddInt64(&t.dropped, 1)
+			continue
+		}
+		t.c <- Block{
+			Samples:  append([][2]float64(nil), samples...),
+			Position: pos,
+		}
+	}
+}
+
+// closeTaps closes all of the Taps.
+func (s *Speaker) closeTaps() {
+	s.tapMu.Lock()
+	defer s.tapMu.Unlock()
+	for _, t := range s.taps {
+		close(t.c)
+	}
+	s.taps = nil
+}
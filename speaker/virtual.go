@@ -0,0 +1,152 @@
+package speaker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// manualBackend is a Backend which drives the playback of its Speaker itself, instead of the
+// Speaker's playback goroutine.
+type manualBackend interface {
+	Backend
+	attach(s *Speaker)
+}
+
+// Virtual is a Backend driven by a manual clock instead of real time, which makes code using a
+// Speaker deterministic and testable. No audio is played, instead, the output is captured.
+//
+// A Speaker with a Virtual Backend doesn't pull any samples on its own. Advancing the clock by n
+// samples pulls exactly n samples from the Speaker's mixer.
+//
+//   v := speaker.NewVirtual()
+//   speaker.InitWithOptions(speaker.Options{SampleRate: sr, BufferSize: 512, Backend: v})
+//   game.PlayExplosion() // calls speaker.Play
+//   v.AdvanceTime(time.Second)
+//   out := v.Output()    // what the user would hear during the first second
+type Virtual struct {
+	mu     sync.Mutex
+	s      *Speaker
+	cfg    Config
+	out    [][2]float64
+	played int
+}
+
+// NewVirtual creates a new Virtual Backend.
+func NewVirtual() *Virtual {
+	return &Virtual{}
+}
+
+// InitVirtual initializes the default Speaker with a new Virtual Backend and returns it. This is a
+// shortcut for testing code which uses the package-level functions.
+func InitVirtual(sampleRate beep.SampleRate) (*Virtual, error) {
+	v := NewVirtual()
+	err := InitWithOptions(Options{
+		SampleRate: sampleRate,
+		BufferSize: sampleRate.N(time.Second / 10),
+		Backend:    v,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Open implements Backend.
+func (v *Virtual) Open(cfg Config) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.cfg = cfg
+	v.out = nil
+	v.played = 0
+	return nil
+}
+
+// Write implements Backend. The Speaker doesn't write to a Virtual Backend, the samples are
+// captured by Advance instead.
+func (v *Virtual) Write(p []byte) (n int, err error) {
+	return len(p), nil
+}
+
+// Latency implements LatencyBackend. The captured samples are considered played immediately.
+func (v *Virtual) Latency() int {
+	return 0
+}
+
+// Close implements Backend.
+func (v *Virtual) Close() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.s = nil
+	return nil
+}
+
+func (v *Virtual) attach(s *Speaker) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.s = s
+}
+
+// Advance moves the clock forward by n samples. It pulls exactly n samples from the Speaker's
+// mixer and appends them to the captured output. It panics if the Backend is not open.
+func (v *Virtual) Advance(n int) {
+	v.mu.Lock()
+	s := v.s
+	v.mu.Unlock()
+	if s == nil {
+		panic("speaker: virtual: advancing a closed backend")
+	}
+
+	// v is not locked while mixing, so that the streamers can use it, e.g. in callbacks
+	samples := make([][2]float64, n)
+	s.mix(samples)
+	atomic.AddInt64(&s.written, int64(n))
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.out = append(v.out, samples...)
+	v.played += n
+}
+
+// AdvanceTime moves the clock forward by d, rounded down to whole samples.
+func (v *Virtual) AdvanceTime(d time.Duration) {
+	v.Advance(v.SampleRate().N(d))
+}
+
+// SampleRate returns the sample rate the Backend was opened with.
+func (v *Virtual) SampleRate() beep.SampleRate {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.cfg.Format.SampleRate
+}
+
+// Played returns the number of samples the clock advanced by since the Backend was opened.
+func (v *Virtual) Played() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.played
+}
+
+// Now returns the time elapsed on the clock since the Backend was opened.
+func (v *Virtual) Now() time.Duration {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.cfg.Format.SampleRate.D(v.played)
+}
+
+// Output returns all of the samples captured since the Backend was opened or since the last call
+// to Reset. The samples are clipped to [-1, +1], just like the audio sent to a real output.
+func (v *Virtual) Output() [][2]float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([][2]float64(nil), v.out...)
+}
+
+// Reset discards the captured output. The clock is not affected.
+func (v *Virtual) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.out = nil
+}
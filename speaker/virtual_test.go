@@ -0,0 +1,294 @@
+package speaker_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+	"github.com/faiface/beep/speaker"
+)
+
+func TestVirtual(t *testing.T) {
+	v, err := speaker.InitVirtual(44100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer speaker.Close()
+
+	data := beeptest.Collect(beeptest.Sine(44100, 440, 0.5, 1000))
+	done := false
+	speaker.Play(beep.Seq(beeptest.Data(data), beep.Callback(func() { done = true })))
+
+	v.Advance(600)
+	if done {
+		t.Fatal("callback called too early")
+	}
+	if got := v.Output(); !reflect.DeepEqual(data[:600], got) {
+		t.Fatal("unexpected output after 600 samples")
+	}
+
+	v.Reset()
+	v.Advance(600)
+	if !done {
+		t.Fatal("callback not called after the streamer drained")
+	}
+	want := append(data[600:], make([][2]float64, 200)...)
+	if got := v.Output(); !reflect.DeepEqual(want, got) {
+		t.Error("unexpected output after the streamer drained")
+	}
+	if v.Played() != 1200 {
+		t.Errorf("expected 1200 played samples, got %d", v.Played())
+	}
+
+	v.AdvanceTime(time.Second)
+	if v.Now() != time.Second+beep.SampleRate(44100).D(1200) {
+		t.Errorf("unexpected clock time: %v", v.Now())
+	}
+}
+
+func TestVirtualClipping(t *testing.T) {
+	v := speaker.NewVirtual()
+	s, err := speaker.New(speaker.Options{SampleRate: 8000, BufferSize: 100, Backend: v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Play(beeptest.Constant([2]float64{0.75, -0.75}, 10), beeptest.Constant([2]float64{0.75, -0.75}, 10))
+	v.Advance(1)
+	if got := v.Output()[0]; got != [2]float64{1, -1} {
+		t.Errorf("expected the output to be clipped, got %v", got)
+	}
+}
+
+func TestVirtualClock(t *testing.T) {
+	v := speaker.NewVirtual()
+	s, err := speaker.New(speaker.Options{SampleRate: 1000, BufferSize: 100, Backend: v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ss := beeptest.Data(make([][2]float64, 5000))
+	s.Play(ss)
+	v.Advance(300)
+
+	s.Lock()
+	p := s.AudiblePosition(ss, 2000)
+	s.Unlock()
+	if s.Played() != 300 || s.Latency() != 0 || p != 300 {
+		t.Errorf("unexpected clock: played %d, latency %v, position %d", s.Played(), s.Latency(), p)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	v := speaker.NewVirtual()
+	s, err := speaker.New(speaker.Options{
+		SampleRate: 8000,
+		BufferSize: 100,
+		Backend:    v,
+		Limiter:    &speaker.LimiterOptions{Threshold: -6, Lookahead: 5 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// quiet, then a loud burst, then quiet again
+	data := append(beeptest.Collect(beeptest.Sine(8000, 100, 0.25, 400)),
+		beeptest.Collect(beeptest.Sine(8000, 100, 2, 400))...)
+	data = append(data, beeptest.Collect(beeptest.Sine(8000, 100, 0.25, 4000))...)
+	s.Play(beeptest.Data(data))
+	v.Advance(len(data))
+
+	const delay = 39 // 5ms at 8kHz, minus one
+	if got := s.Latency(); got != beep.SampleRate(8000).D(delay) {
+		t.Errorf("expected the latency to include the limiter delay, got %v", got)
+	}
+	out := v.Output()
+	ceiling := math.Pow(10, -6.0/20)
+	for i, sample := range out {
+		if math.Abs(sample[0]) > ceiling+1e-9 {
+			t.Fatalf("sample %d exceeds the threshold: %v", i, sample[0])
+		}
+	}
+	if !reflect.DeepEqual(data[:300], out[delay:delay+300]) {
+		t.Error("expected the quiet part before the burst to pass unchanged")
+	}
+	if last := len(out) - 1; math.Abs(out[last][0]-data[last-delay][0]) > 0.01 {
+		t.Error("expected the gain to recover after the burst")
+	}
+	if gr := s.GainReduction(); math.Abs(gr-12) > 0.1 {
+		t.Errorf("expected about 12dB of gain reduction, got %v", gr)
+	}
+	if gr := s.GainReduction(); gr != 0 {
+		t.Errorf("expected the gain reduction to reset, got %v", gr)
+	}
+}
+
+func TestMasterVolume(t *testing.T) {
+	v := speaker.NewVirtual()
+	s, err := speaker.New(speaker.Options{SampleRate: 8000, BufferSize: 100, Backend: v, Volume: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Play(beeptest.Constant([2]float64{0.5, -0.5}, 30))
+	v.Advance(10)
+	s.SetMuted(true)
+	v.Advance(10)
+	s.SetMuted(false)
+	s.SetVolume(2)
+	v.Advance(10)
+
+	out := v.Output()
+	for i, want := range []float64{0.25, 0, 1} {
+		if out[i*10] != [2]float64{want, -want} {
+			t.Errorf("block %d: expected %v, got %v", i, want, out[i*10])
+		}
+	}
+}
+
+func TestTap(t *testing.T) {
+	v := speaker.NewVirtual()
+	s, err := speaker.New(speaker.Options{SampleRate: 8000, BufferSize: 100, Backend: v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tap := s.NewTap(2)
+	s.Play(beeptest.Ramp(50))
+	for i := 0; i < 3; i++ {
+		v.Advance(20)
+	}
+
+	if tap.Dropped() != 1 {
+		t.Errorf("expected 1 dropped block, got %d", tap.Dropped())
+	}
+	out := v.Output()
+	for i := 0; i < 2; i++ {
+		block := <-tap.C
+		if block.Position != i*20 {
+			t.Errorf("block %d: expected position %d, got %d", i, i*20, block.Position)
+		}
+		if !reflect.DeepEqual(block.Samples, out[i*20:(i+1)*20]) {
+			t.Errorf("block %d: samples differ from the output", i)
+		}
+	}
+
+	tap.Close()
+	if _, ok := <-tap.C; ok {
+		t.Error("expected the channel to be closed")
+	}
+	v.Advance(20) // must not send to the closed tap
+}
+
+func TestSuspendAndIdle(t *testing.T) {
+	v := speaker.NewVirtual()
+	s, err := speaker.New(speaker.Options{
+		SampleRate:  1000,
+		BufferSize:  10,
+		Backend:     v,
+		IdleTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ramp := beeptest.Ramp(40)
+	s.Play(ramp)
+	v.Advance(10)
+	s.Suspend()
+	v.Advance(10)
+	if ramp.Position() != 10 {
+		t.Errorf("expected the streamer to pause while suspended, position %d", ramp.Position())
+	}
+	s.Resume()
+	v.Advance(30)
+	if ramp.Position() != 40 {
+		t.Errorf("expected the streamer to continue after resume, position %d", ramp.Position())
+	}
+
+	v.Advance(40)
+	if s.Idle() {
+		t.Error("idle too early")
+	}
+	v.Advance(10)
+	if !s.Idle() {
+		t.Error("expected the speaker to be idle")
+	}
+
+	s.Play(beeptest.Constant([2]float64{0.5, 0.5}, 10))
+	if s.Idle() {
+		t.Error("expected Play to wake the speaker up")
+	}
+	v.Reset()
+	v.Advance(10)
+	if out := v.Output(); out[0] != [2]float64{0.5, 0.5} {
+		t.Errorf("unexpected output after waking up: %v", out[0])
+	}
+}
+
+func TestPlayAndWait(t *testing.T) {
+	v := speaker.NewVirtual()
+	s, err := speaker.New(speaker.Options{SampleRate: 1000, BufferSize: 10, Backend: v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// advance the clock in the background, 10 samples at a time
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				v.Advance(10)
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+
+	ramp := beeptest.Ramp(100)
+	if err := s.PlayAndWait(context.Background(), ramp, beeptest.Ramp(50)); err != nil {
+		t.Fatal(err)
+	}
+	if ramp.Position() != 100 {
+		t.Errorf("returned before the streamer drained, position %d", ramp.Position())
+	}
+
+	failing := errStreamer{errors.New("failure")}
+	if err := s.PlayAndWait(context.Background(), beeptest.Ramp(1000000), failing); err != failing.err {
+		t.Errorf("expected the streamer's error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.PlayAndWait(ctx, beeptest.Ramp(1000000)); err != context.DeadlineExceeded {
+		t.Errorf("expected the context's error, got %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	v.Reset()
+	time.Sleep(10 * time.Millisecond)
+	for i, sample := range v.Output() {
+		if sample != [2]float64{} {
+			t.Fatalf("expected the streamers to be removed, sample %d is %v", i, sample)
+		}
+	}
+}
+
+type errStreamer struct{ err error }
+
+func (e errStreamer) Stream(samples [][2]float64) (n int, ok bool) { return 0, false }
+func (e errStreamer) Err() error                                   { return e.err }
@@ -0,0 +1,134 @@
+package speaker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/faiface/beep"
+)
+
+// PlayAndWait plays the Streamers through the default Speaker and waits until they are heard.
+func PlayAndWait(ctx context.Context, s ...beep.Streamer) error {
+	return defaultSpeaker.PlayAndWait(ctx, s...)
+}
+
+// PlayAndWait starts playing all provided Streamers through the Speaker and blocks until all of
+// them drain and their last samples are actually heard, accounting for the latency of the
+// Speaker. It replaces the common pattern of playing beep.Seq(s, beep.Callback(...)) and waiting
+// for the callback.
+//
+//   err := speaker.PlayAndWait(ctx, streamer)
+//
+// If ctx is cancelled before that, the Streamers are removed from the Speaker and ctx.Err() is
+// returned. If any of the Streamers fails, the others are removed and the error of the failed
+// one is returned right away. If the playback stops, because the Speaker is closed or writing to
+// its Backend fails, an error is returned too.
+func (s *Speaker) PlayAndWait(ctx context.Context, st ...beep.Streamer) error {
+	if len(st) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	exited := s.exited
+	s.mu.Unlock()
+
+	w := &waitGroup{left: len(st), done: make(chan struct{})}
+	waiting := make([]beep.Streamer, len(st))
+	for i := range st {
+		waiting[i] = &waitStreamer{s: st[i], w: w, sp: s}
+	}
+	s.Play(waiting...)
+
+	select {
+	case <-w.done:
+	case <-exited:
+		return s.stopped()
+	case <-ctx.Done():
+		s.mu.Lock()
+		w.stopped = true
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	// wait until the end of the Streamers is played
+	for {
+		left := w.end - int64(s.Played())
+		if left <= 0 {
+			return nil
+		}
+		d := s.SampleRate().D(int(left))
+		if d < time.Millisecond {
+			d = time.Millisecond
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-t.C:
+		case <-exited:
+			t.Stop()
+			return s.stopped()
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// stopped returns the error of a playback which stopped before the Streamers were heard.
+func (s *Speaker) stopped() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	return errors.New("speaker closed")
+}
+
+// waitGroup tracks the Streamers of a PlayAndWait call. It's only modified while the Speaker is
+// locked and read after done is closed.
+type waitGroup struct {
+	left    int   // number of the Streamers which are not drained
+	err     error // the first error of the Streamers
+	end     int64 // output position by which all of the Streamers drained
+	stopped bool  // the Streamers should be removed
+	done    chan struct{}
+}
+
+// waitStreamer reports the draining of a Streamer played by PlayAndWait to its waitGroup.
+type waitStreamer struct {
+	s       beep.Streamer
+	w       *waitGroup
+	sp      *Speaker
+	drained bool
+}
+
+func (ws *waitStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	if ws.w.stopped || ws.drained {
+		return 0, false
+	}
+	n, ok = ws.s.Stream(samples)
+	if !ok || n < len(samples) {
+		ws.drained = true
+		if err := ws.s.Err(); err != nil {
+			ws.w.err, ws.w.stopped = err, true
+			close(ws.w.done)
+			return n, ok
+		}
+		ws.w.left--
+		if ws.w.left == 0 {
+			// the Speaker counts the block being mixed as pulled already, so this is the end of
+			// the block, plus the delay of the limiter
+			ws.w.end = atomic.LoadInt64(&ws.sp.pulled) + atomic.LoadInt64(&ws.sp.delay)
+			close(ws.w.done)
+		}
+	}
+	return n, ok
+}
+
+func (ws *waitStreamer) Err() error {
+	return ws.s.Err()
+}
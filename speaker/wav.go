@@ -0,0 +1,98 @@
+package speaker
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/faiface/beep/wav"
+	"github.com/pkg/errors"
+)
+
+// WAVFile returns a Backend which writes the audio to a WAV file at path. The file is created (or
+// truncated) when the Backend is opened and finalized when it's closed. The audio is written at
+// real-time pace, so the file contains exactly what would be heard.
+//
+// Close the Speaker to finalize the file. The header is also updated about once a second, so a
+// file of a killed process is readable, but misses up to a second of the audio.
+func WAVFile(path string) Backend {
+	return &wavBackend{path: path}
+}
+
+type wavBackend struct {
+	path    string
+	f       *os.File
+	w       *bufio.Writer
+	cfg     Config
+	written int
+	header  int // value of written when the header was last updated
+	pacer   pacer
+}
+
+func (wb *wavBackend) Open(cfg Config) error {
+	f, err := os.Create(wb.path)
+	if err != nil {
+		return errors.Wrap(err, "wav file")
+	}
+	wb.f = f
+	wb.w = bufio.NewWriter(f)
+	wb.written, wb.header = 0, 0
+	wb.cfg = cfg
+	if err := wav.WriteHeader(wb.w, cfg.Format, cfg.Float, -1); err != nil {
+		f.Close()
+		return errors.Wrap(err, "wav file")
+	}
+	wb.pacer.reset(cfg)
+	return nil
+}
+
+func (wb *wavBackend) Write(p []byte) (n int, err error) {
+	wb.pacer.wait(len(p))
+	n, err = wb.w.Write(p)
+	wb.written += n
+	if err != nil {
+		return n, errors.Wrap(err, "wav file")
+	}
+	if wb.written-wb.header >= int(wb.cfg.Format.SampleRate)*wb.cfg.Format.Width() {
+		if err := wb.updateHeader(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// updateHeader writes the header with the size of the audio written so far.
+func (wb *wavBackend) updateHeader() error {
+	if err := wb.w.Flush(); err != nil {
+		return errors.Wrap(err, "wav file")
+	}
+	if _, err := wb.f.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "wav file")
+	}
+	if err := wav.WriteHeader(wb.f, wb.cfg.Format, wb.cfg.Float, wb.written); err != nil {
+		return errors.Wrap(err, "wav file")
+	}
+	if _, err := wb.f.Seek(0, io.SeekEnd); err != nil {
+		return errors.Wrap(err, "wav file")
+	}
+	wb.header = wb.written
+	return nil
+}
+
+func (wb *wavBackend) Latency() int {
+	return wb.pacer.latency()
+}
+
+func (wb *wavBackend) Close() (err error) {
+	if wb.f == nil {
+		return nil
+	}
+	defer func() {
+		if cerr := wb.f.Close(); err == nil && cerr != nil {
+			err = errors.Wrap(cerr, "wav file")
+		}
+		wb.f, wb.w = nil, nil
+	}()
+
+	return wb.updateHeader()
+}
@@ -0,0 +1,51 @@
+package speaker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+)
+
+func TestWAVFileHeaderUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "speaker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.wav")
+
+	// the big buffer lets the pacer write the whole second at once
+	cfg := Config{
+		Format:     beep.Format{SampleRate: 1000, NumChannels: 2, Precision: 2},
+		BufferSize: 2000,
+	}
+	b := WAVFile(path)
+	if err := b.Open(cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	buf := make([]byte, 100*cfg.Format.Width())
+	for i := 0; i < 12; i++ {
+		if _, err := b.Write(buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the file is readable without closing the Backend, as if the process was killed
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d, _, err := wav.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 1000 {
+		t.Errorf("expected the header updated after a second of audio, got %d samples", d.Len())
+	}
+}
@@ -0,0 +1,237 @@
+package beep
+
+import (
+	"fmt"
+	"math"
+)
+
+// ViolationKind classifies the ways in which a Streamer can break the contract documented on the
+// Streamer interface.
+type ViolationKind int
+
+// Violations detected by a Validator.
+const (
+	// InvalidCount means Stream returned n < 0 or n > len(samples).
+	InvalidCount ViolationKind = iota
+
+	// InvalidPattern means Stream returned a combination of n and ok which is not one of the 3
+	// valid return patterns, such as n > 0 && !ok or n == 0 && ok.
+	InvalidPattern
+
+	// OkAfterDrain means Stream returned samples or ok == true after it had already drained,
+	// either by streaming less than requested or by returning !ok.
+	OkAfterDrain
+
+	// WriteBeyondN means Stream modified samples outside samples[:n].
+	WriteBeyondN
+
+	// UnwrittenSample means Stream reported a sample in samples[:n] as streamed without writing it.
+	UnwrittenSample
+
+	// InvalidSample means Stream produced a NaN or an infinite sample value.
+	InvalidSample
+
+	// ErrorWithoutDrain means Err returned a non-nil error, but Stream did not return 0, false.
+	ErrorWithoutDrain
+)
+
+func (k ViolationKind) String() string {
+	switch k {
+	case InvalidCount:
+		return "invalid sample count"
+	case InvalidPattern:
+		return "invalid return pattern"
+	case OkAfterDrain:
+		return "ok after drain"
+	case WriteBeyondN:
+		return "write beyond n"
+	case UnwrittenSample:
+		return "unwritten sample"
+	case InvalidSample:
+		return "invalid sample value"
+	case ErrorWithoutDrain:
+		return "error without drain"
+	default:
+		return fmt.Sprintf("ViolationKind(%d)", int(k))
+	}
+}
+
+// Violation describes a single breach of the Streamer contract, together with the context of the
+// Stream call in which it happened.
+type Violation struct {
+	Kind ViolationKind
+
+	// Call is the zero-based index of the offending Stream call.
+	Call int
+
+	// Position is the number of samples streamed by all of the previous Stream calls.
+	Position int
+
+	// Len, N and Ok are the length of the samples slice and the values returned by the call.
+	Len int
+	N   int
+	Ok  bool
+
+	// Index is the index into the samples slice of the offending sample, or -1 if the violation
+	// is not related to a particular sample.
+	Index int
+
+	// Err is the error returned by the Streamer's Err method after the call.
+	Err error
+}
+
+func (v Violation) Error() string {
+	msg := fmt.Sprintf("beep: streamer contract violation: %v (call %d, position %d, len %d, n %d, ok %v",
+		v.Kind, v.Call, v.Position, v.Len, v.N, v.Ok)
+	if v.Index >= 0 {
+		msg += fmt.Sprintf(", index %d", v.Index)
+	}
+	if v.Err != nil {
+		msg += fmt.Sprintf(", err %v", v.Err)
+	}
+	return msg + ")"
+}
+
+// canary is a NaN with a payload which is very unlikely to be produced by any real Streamer. The
+// Validator fills the unused parts of the samples with it to detect out-of-bounds writes.
+var canary = math.Float64frombits(0x7ff8beefdeadbeef)
+
+func isCanary(x float64) bool {
+	return math.Float64bits(x) == math.Float64bits(canary)
+}
+
+// Validate wraps s in a Validator, which checks that every call to Stream and Err of s obeys the
+// Streamer contract. This is useful in tests and while debugging custom Streamers.
+//
+//   v := beep.Validate(s)
+//   v.OnViolation = func(err beep.Violation) { log.Println(err) }
+//   speaker.Play(v)
+//
+// The Validator streams exactly what s streams. It never fixes the violations, it only reports
+// them.
+func Validate(s Streamer) *Validator {
+	return &Validator{s: s}
+}
+
+// Validator is a Streamer created by Validate. All of the detected violations are recorded and
+// passed to the OnViolation callback, if set.
+//
+// Checking for writes beyond n requires filling the unused part of the samples with special
+// values, so a Validator is slower than the wrapped Streamer.
+type Validator struct {
+	// OnViolation is called for each detected violation, right after the offending Stream
+	// call. It's called while the speaker is locked, if the Validator is playing.
+	OnViolation func(v Violation)
+
+	s          Streamer
+	violations []Violation
+	tmp        [][2]float64 // backup of the caller's samples
+	calls      int          // number of Stream calls so far
+	pos        int          // number of samples streamed so far
+	drained    bool         // s is drained, only 0, false is valid now
+}
+
+// Stream streams the wrapped Streamer and checks its behavior.
+func (v *Validator) Stream(samples [][2]float64) (n int, ok bool) {
+	if cap(v.tmp) < len(samples) {
+		v.tmp = make([][2]float64, len(samples))
+	}
+	tmp := v.tmp[:len(samples)]
+	copy(tmp, samples)
+	for i := range samples {
+		samples[i] = [2]float64{canary, canary}
+	}
+
+	n, ok = v.s.Stream(samples)
+	err := v.s.Err()
+
+	report := func(kind ViolationKind, index int) {
+		v.report(Violation{
+			Kind:     kind,
+			Call:     v.calls,
+			Position: v.pos,
+			Len:      len(samples),
+			N:        n,
+			Ok:       ok,
+			Index:    index,
+			Err:      err,
+		})
+	}
+
+	valid := n
+	switch {
+	case n < 0 || n > len(samples):
+		report(InvalidCount, -1)
+		if n < 0 {
+			valid = 0
+		} else {
+			valid = len(samples)
+		}
+	case !ok && n > 0:
+		report(InvalidPattern, -1)
+	case ok && n == 0 && len(samples) > 0:
+		report(InvalidPattern, -1)
+	}
+
+	if v.drained && (n != 0 || ok) {
+		report(OkAfterDrain, -1)
+	}
+	if err != nil && (n != 0 || ok) {
+		report(ErrorWithoutDrain, -1)
+	}
+
+	// only the first offending sample of each kind is reported to avoid flooding
+	unwritten, invalid := -1, -1
+	for i := range samples[:valid] {
+		l, r := samples[i][0], samples[i][1]
+		switch {
+		case isCanary(l) || isCanary(r):
+			if unwritten < 0 {
+				unwritten = i
+			}
+		case math.IsNaN(l) || math.IsNaN(r) || math.IsInf(l, 0) || math.IsInf(r, 0):
+			if invalid < 0 {
+				invalid = i
+			}
+		}
+	}
+	if unwritten >= 0 {
+		report(UnwrittenSample, unwritten)
+	}
+	if invalid >= 0 {
+		report(InvalidSample, invalid)
+	}
+	for i := valid; i < len(samples); i++ {
+		if !isCanary(samples[i][0]) || !isCanary(samples[i][1]) {
+			report(WriteBeyondN, i)
+			break
+		}
+	}
+
+	// restore whatever the caller had beyond n, the Validator itself must obey the contract
+	copy(samples[valid:], tmp[valid:])
+
+	if !ok || err != nil || valid < len(samples) {
+		v.drained = true
+	}
+	v.calls++
+	v.pos += valid
+	return n, ok
+}
+
+// Err propagates the wrapped Streamer's errors.
+func (v *Validator) Err() error {
+	return v.s.Err()
+}
+
+// Violations returns all of the violations detected so far.
+func (v *Validator) Violations() []Violation {
+	return v.violations
+}
+
+func (v *Validator) report(violation Violation) {
+	v.violations = append(v.violations, violation)
+	if v.OnViolation != nil {
+		v.OnViolation(violation)
+	}
+}
@@ -0,0 +1,171 @@
+package beep_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/beeptest"
+)
+
+// violations drains s through a Validator using slices of the given size and returns the kinds of
+// all of the detected violations.
+func violations(s beep.Streamer, size int) []beep.ViolationKind {
+	v := beep.Validate(s)
+	buf := make([][2]float64, size)
+	for i := 0; i < 10; i++ {
+		v.Stream(buf)
+	}
+	var kinds []beep.ViolationKind
+	for _, violation := range v.Violations() {
+		kinds = append(kinds, violation.Kind)
+	}
+	return kinds
+}
+
+type errStreamer struct {
+	calls int
+	err   error
+}
+
+func (es *errStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	es.calls++
+	if es.calls > 1 {
+		es.err = errors.New("broken")
+	}
+	for i := range samples {
+		samples[i] = [2]float64{}
+	}
+	return len(samples), true
+}
+
+func (es *errStreamer) Err() error {
+	return es.err
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    beep.Streamer
+		want beep.ViolationKind
+	}{
+		{
+			name: "samples with !ok",
+			s: beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+				samples[0] = [2]float64{}
+				return 1, false
+			}),
+			want: beep.InvalidPattern,
+		},
+		{
+			name: "ok after partial",
+			s: beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+				samples[0] = [2]float64{}
+				return 1, true
+			}),
+			want: beep.OkAfterDrain,
+		},
+		{
+			name: "too many samples",
+			s: beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+				return len(samples) + 1, true
+			}),
+			want: beep.InvalidCount,
+		},
+		{
+			name: "write beyond n",
+			s: beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+				for i := range samples {
+					samples[i] = [2]float64{}
+				}
+				return len(samples) / 2, true
+			}),
+			want: beep.WriteBeyondN,
+		},
+		{
+			name: "unwritten samples",
+			s: beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+				return len(samples), true
+			}),
+			want: beep.UnwrittenSample,
+		},
+		{
+			name: "NaN",
+			s: beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+				for i := range samples {
+					samples[i] = [2]float64{0, math.NaN()}
+				}
+				return len(samples), true
+			}),
+			want: beep.InvalidSample,
+		},
+		{
+			name: "Inf",
+			s: beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+				for i := range samples {
+					samples[i] = [2]float64{math.Inf(-1), 0}
+				}
+				return len(samples), true
+			}),
+			want: beep.InvalidSample,
+		},
+		{
+			name: "error without drain",
+			s:    &errStreamer{},
+			want: beep.ErrorWithoutDrain,
+		},
+	}
+
+	for _, tt := range tests {
+		kinds := violations(tt.s, 16)
+		found := false
+		for _, kind := range kinds {
+			found = found || kind == tt.want
+		}
+		if !found {
+			t.Errorf("%s: expected %v violation, got %v", tt.name, tt.want, kinds)
+		}
+	}
+}
+
+func TestValidateRestoresSamples(t *testing.T) {
+	s, data := randomDataStreamer(10)
+	v := beep.Validate(s)
+
+	buf := make([][2]float64, 16)
+	for i := range buf {
+		buf[i] = [2]float64{0.5, -0.5}
+	}
+	n, ok := v.Stream(buf)
+	if n != 10 || !ok {
+		t.Fatalf("unexpected Stream result: %v, %v", n, ok)
+	}
+	for i := range buf[:n] {
+		if buf[i] != data[i] {
+			t.Fatalf("sample %d was not passed through: %v != %v", i, buf[i], data[i])
+		}
+	}
+	for i := range buf[n:] {
+		if buf[n+i] != [2]float64{0.5, -0.5} {
+			t.Fatalf("sample %d beyond n was modified: %v", n+i, buf[n+i])
+		}
+	}
+	if len(v.Violations()) > 0 {
+		t.Errorf("unexpected violations: %v", v.Violations())
+	}
+}
+
+func TestValidateCompositors(t *testing.T) {
+	s := func() beep.StreamSeeker {
+		s, _ := randomDataStreamer(3000)
+		return s
+	}
+	beeptest.Check(t, beep.Silence(5000), -1)
+	beeptest.Check(t, beep.Silence(-1), 20000)
+	beeptest.Check(t, beep.Take(2000, s()), -1)
+	beeptest.Check(t, beep.Loop(3, s()), -1)
+	beeptest.Check(t, beep.Seq(s(), s(), s()), -1)
+	beeptest.Check(t, beep.Resample(3, 44100, 48000, s()), -1)
+	beeptest.Check(t, &beep.Ctrl{Streamer: s()}, -1)
+}
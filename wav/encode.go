@@ -27,22 +27,7 @@ func Encode(w io.WriteSeeker, s beep.Streamer, format beep.Format) (err error) {
 		return errors.New("wav: unsupported precision, 1, 2 or 3 is supported")
 	}
 
-	h := header{
-		RiffMark:      [4]byte{'R', 'I', 'F', 'F'},
-		FileSize:      -1, // finalization
-		WaveMark:      [4]byte{'W', 'A', 'V', 'E'},
-		FmtMark:       [4]byte{'f', 'm', 't', ' '},
-		FormatSize:    16,
-		FormatType:    1,
-		NumChans:      int16(format.NumChannels),
-		SampleRate:    int32(format.SampleRate),
-		ByteRate:      int32(int(format.SampleRate) * format.NumChannels * format.Precision),
-		BytesPerFrame: int16(format.NumChannels * format.Precision),
-		BitsPerSample: int16(format.Precision) * 8,
-		DataMark:      [4]byte{'d', 'a', 't', 'a'},
-		DataSize:      -1, // finalization
-	}
-	if err := binary.Write(w, binary.LittleEndian, &h); err != nil {
+	if err := WriteHeader(w, format, false, -1); err != nil {
 		return err
 	}
 
@@ -81,12 +66,10 @@ func Encode(w io.WriteSeeker, s beep.Streamer, format beep.Format) (err error) {
 	}
 
 	// finalize header
-	h.FileSize = int32(44 + written) // 44 is the size of the header
-	h.DataSize = int32(written)
 	if _, err := w.Seek(0, io.SeekStart); err != nil {
 		return err
 	}
-	if err := binary.Write(w, binary.LittleEndian, &h); err != nil {
+	if err := WriteHeader(w, format, false, written); err != nil {
 		return err
 	}
 	if _, err := w.Seek(0, io.SeekEnd); err != nil {
@@ -95,3 +78,34 @@ func Encode(w io.WriteSeeker, s beep.Streamer, format beep.Format) (err error) {
 
 	return nil
 }
+
+// WriteHeader writes the header of a WAVE file with dataSize bytes of audio data in the format
+// to w. If float is true, the samples are IEEE floats instead of PCM.
+//
+// Writers which don't know the size of the data in advance can pass a negative dataSize and
+// rewrite the header when they're done, like Encode does.
+func WriteHeader(w io.Writer, format beep.Format, float bool, dataSize int) error {
+	h := header{
+		RiffMark:      [4]byte{'R', 'I', 'F', 'F'},
+		FileSize:      -1, // finalization
+		WaveMark:      [4]byte{'W', 'A', 'V', 'E'},
+		FmtMark:       [4]byte{'f', 'm', 't', ' '},
+		FormatSize:    16,
+		FormatType:    1, // PCM
+		NumChans:      int16(format.NumChannels),
+		SampleRate:    int32(format.SampleRate),
+		ByteRate:      int32(int(format.SampleRate) * format.Width()),
+		BytesPerFrame: int16(format.Width()),
+		BitsPerSample: int16(format.Precision) * 8,
+		DataMark:      [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      -1, // finalization
+	}
+	if float {
+		h.FormatType = 3 // IEEE float
+	}
+	if dataSize >= 0 {
+		h.FileSize = int32(44 - 8 + dataSize) // 44 is the size of the header
+		h.DataSize = int32(dataSize)
+	}
+	return binary.Write(w, binary.LittleEndian, &h)
+}
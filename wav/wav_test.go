@@ -0,0 +1,120 @@
+package wav_test
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	want := make([][2]float64, 1000)
+	for i := range want {
+		want[i][0] = math.Sin(float64(i) / 10)
+		want[i][1] = -0.5 * math.Sin(float64(i)/7)
+	}
+	want[0] = [2]float64{-1, 1}
+
+	for _, precision := range []int{2, 3} {
+		for _, numChannels := range []int{1, 2} {
+			format := beep.Format{SampleRate: 44100, NumChannels: numChannels, Precision: precision}
+			got := roundTrip(t, want, format)
+			if len(got) != len(want) {
+				t.Fatalf("precision %d, %d channels: got %d samples, want %d", precision, numChannels, len(got), len(want))
+			}
+
+			lsb := 1 / math.Pow(2, float64(precision*8-1))
+			for i := range want {
+				w := want[i]
+				if numChannels == 1 {
+					w[0] = (w[0] + w[1]) / 2
+					w[1] = w[0]
+				}
+				for c := range w {
+					if math.Abs(got[i][c]-w[c]) > lsb {
+						t.Fatalf("precision %d, %d channels: sample %d: got %v, want %v", precision, numChannels, i, got[i], w)
+					}
+				}
+			}
+		}
+	}
+}
+
+func roundTrip(t *testing.T, samples [][2]float64, format beep.Format) [][2]float64 {
+	t.Helper()
+
+	f, err := ioutil.TempFile("", "beep-wav-*.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	i := 0
+	s := beep.StreamerFunc(func(buf [][2]float64) (n int, ok bool) {
+		if i >= len(samples) {
+			return 0, false
+		}
+		n = copy(buf, samples[i:])
+		i += n
+		return n, true
+	})
+	if err := wav.Encode(f, s, format); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	d, _, err := wav.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][2]float64
+	buf := make([][2]float64, 512)
+	for {
+		n, ok := d.Stream(buf)
+		got = append(got, buf[:n]...)
+		if !ok {
+			break
+		}
+	}
+	if err := d.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestEncodeHeader(t *testing.T) {
+	f, err := ioutil.TempFile("", "beep-wav-*.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	format := beep.Format{SampleRate: 44100, NumChannels: 2, Precision: 2}
+	if err := wav.Encode(f, beep.Take(100, beep.Silence(-1)), format); err != nil {
+		t.Fatal(err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header [44]byte
+	if _, err := f.ReadAt(header[:], 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// the RIFF chunk size excludes the 8 bytes of the RIFF mark and the size itself
+	if size := binary.LittleEndian.Uint32(header[4:]); int64(size) != info.Size()-8 {
+		t.Errorf("expected the RIFF chunk size of %d, got %d", info.Size()-8, size)
+	}
+	if size := binary.LittleEndian.Uint32(header[40:]); size != 100*4 {
+		t.Errorf("expected the data size of %d, got %d", 100*4, size)
+	}
+}